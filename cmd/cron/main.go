@@ -1,20 +1,35 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"logistic-app/internal/adapters/cron"
 	"logistic-app/internal/adapters/db"
+	"logistic-app/internal/adapters/provider"
 	"logistic-app/internal/app/service"
+	"logistic-app/internal/common/logger"
+	"logistic-app/internal/common/telemetry"
+	"os"
 )
 
 func main() {
+	logger.Init()
+
+	shutdown, err := telemetry.Init(context.Background(), "logistic-cron")
+	if err != nil {
+		slog.Error("could not initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdown(context.Background())
+
 	repo, err := db.NewPostgresDB()
 	if err != nil {
-		log.Fatal("could not connect to postgres: ", err)
+		slog.Error("could not connect to postgres", "error", err)
+		os.Exit(1)
 	}
 	defer repo.Close()
 
-	logSer := service.NewLogisticService(repo)
+	logSer := service.WithTracing(service.NewLogisticService(repo, provider.NewClient()))
 	scheduler := cron.NewScheduler(logSer)
 
 	scheduler.Run()
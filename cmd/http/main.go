@@ -1,21 +1,48 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"logistic-app/internal/adapters/db"
 	"logistic-app/internal/adapters/http"
+	"logistic-app/internal/adapters/http/middlewares"
+	"logistic-app/internal/adapters/provider"
+	"logistic-app/internal/app/ports"
 	"logistic-app/internal/app/service"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/logger"
+	"logistic-app/internal/common/telemetry"
+	"os"
 )
 
 func main() {
+	logger.Init()
+
+	if err := service.CheckSelectionStrategy(); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	shutdown, err := telemetry.Init(context.Background(), "logistic-http")
+	if err != nil {
+		slog.Error("could not initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdown(context.Background())
+
 	repo, err := db.NewPostgresDB()
 	if err != nil {
-		log.Fatal("could not connect to postgres: ", err)
+		slog.Error("could not connect to postgres", "error", err)
+		os.Exit(1)
 	}
 	defer repo.Close()
 
-	logSer := service.NewLogisticService(repo)
-	server := http.NewServer(logSer)
+	logSer := service.WithTracing(service.NewLogisticService(repo, provider.NewClient()))
+	var rateLimitStore ports.RateLimitStore = middlewares.NewMemoryRateLimitStore()
+	if configs.RateLimitStore == "postgres" {
+		rateLimitStore = repo
+	}
+	server := http.NewServer(logSer, rateLimitStore, repo)
 
 	server.Run()
 }
@@ -1,7 +1,7 @@
 package cron
 
 import (
-	"log"
+	"log/slog"
 	"logistic-app/internal/app/ports"
 )
 
@@ -16,6 +16,6 @@ func NewScheduler(service ports.Service) *Scheduler {
 }
 
 func (s *Scheduler) Run() {
-	log.Println("Running scheduler for order update: ")
+	slog.Info("running scheduler for order update")
 	s.service.ScheduleUpdateOrderStatus()
 }
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+)
+
+func (p *Postgres) ListAddresses(ctx context.Context, customerID uint) ([]*domain.Address, *errors.AppError) {
+	var addresses []*domain.Address
+	result := p.db.WithContext(ctx).Where("customer_id = ?", customerID).Order("is_default DESC, id").Find(&addresses)
+	return addresses, errors.ConvertGormErrors(result.Error)
+}
+
+// GetAddress returns the address when it belongs to the customer.
+func (p *Postgres) GetAddress(ctx context.Context, addressID, customerID uint) (*domain.Address, *errors.AppError) {
+	var address *domain.Address
+	result := p.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&address, addressID)
+	return address, errors.ConvertGormErrors(result.Error)
+}
+
+// CreateAddress saves the address, the first address of a customer is its default.
+func (p *Postgres) CreateAddress(ctx context.Context, address *domain.Address) (*domain.Address, *errors.AppError) {
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := lockCustomer(tx, address.CustomerID); e != nil {
+			return e
+		}
+		var count int64
+		if e := tx.Model(&domain.Address{}).Where("customer_id = ?", address.CustomerID).Count(&count).Error; e != nil {
+			return e
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if e := clearDefaultAddress(tx, address.CustomerID); e != nil {
+				return e
+			}
+		}
+		return tx.Create(&address).Error
+	})
+	return address, errors.ConvertGormErrors(e)
+}
+
+// UpdateAddress applies the given fields to the address of the customer, making it the
+// default address unsets the previous one.
+func (p *Postgres) UpdateAddress(ctx context.Context, customerID uint, update *domain.AddressUpdateRequest) (*domain.Address, *errors.AppError) {
+	var address *domain.Address
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := lockCustomer(tx, customerID); e != nil {
+			return e
+		}
+		if e := tx.Where("customer_id = ?", customerID).First(&address, update.AddressID).Error; e != nil {
+			return e
+		}
+
+		changes := make(map[string]any)
+		set := func(column string, value any, ok bool) {
+			if ok {
+				changes[column] = value
+			}
+		}
+		set("label", update.Label, update.Label != nil)
+		set("is_default", update.IsDefault, update.IsDefault != nil)
+		set("city", update.City, update.City != nil)
+		set("street", update.Street, update.Street != nil)
+		set("unit", update.Unit, update.Unit != nil)
+		set("postal_code", update.PostalCode, update.PostalCode != nil)
+		set("latitude", update.Latitude, update.Latitude != nil)
+		set("longitude", update.Longitude, update.Longitude != nil)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if update.IsDefault != nil && *update.IsDefault && !address.IsDefault {
+			if e := clearDefaultAddress(tx, customerID); e != nil {
+				return e
+			}
+		}
+		if e := tx.Model(address).Updates(changes).Error; e != nil {
+			return e
+		}
+		return tx.First(&address, address.ID).Error
+	})
+	if e != nil {
+		return nil, errors.ConvertGormErrors(e)
+	}
+	return address, nil
+}
+
+// DeleteAddress deletes the address of the customer, orders keep their copy of it.
+func (p *Postgres) DeleteAddress(ctx context.Context, addressID, customerID uint) (*domain.Address, *errors.AppError) {
+	address := &domain.Address{}
+	result := p.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("customer_id = ?", customerID).
+		Delete(address, addressID)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = gorm.ErrRecordNotFound
+	}
+	return address, errors.ConvertGormErrors(result.Error)
+}
+
+// lockCustomer serializes the changes of the addresses of the customer, so it never has two default addresses.
+func lockCustomer(tx *gorm.DB, customerID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&domain.Customer{}, customerID).Error
+}
+
+func clearDefaultAddress(tx *gorm.DB, customerID uint) error {
+	return tx.Model(&domain.Address{}).
+		Where("customer_id = ? AND is_default", customerID).
+		Update("is_default", false).Error
+}
+
+// addressOf returns the saved address of the customer with the ID, or its default address when the ID is nil.
+// Customers without saved addresses fall back to the address of their profile.
+func addressOf(tx *gorm.DB, customer *domain.Customer, addressID *uint) (*domain.Address, error) {
+	locked := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("customer_id = ?", customer.ID)
+	if addressID != nil {
+		var address *domain.Address
+		return address, locked.First(&address, *addressID).Error
+	}
+
+	var addresses []*domain.Address
+	if e := locked.Where("is_default").Limit(1).Find(&addresses).Error; e != nil {
+		return nil, e
+	}
+	if len(addresses) > 0 {
+		return addresses[0], nil
+	}
+	return &domain.Address{Street: customer.Address, PostalCode: customer.PostalCode}, nil
+}
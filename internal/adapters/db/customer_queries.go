@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"time"
+)
+
+// UpdateCustomer applies the update to the customer and records an audit for every field that changed,
+// in a single transaction.
+func (p *Postgres) UpdateCustomer(ctx context.Context, customerID uint, update *domain.CustomerUpdate) (*domain.Customer, *errors.AppError) {
+	var customer *domain.Customer
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; e != nil {
+			return e
+		}
+
+		changes := make(map[string]any)
+		var audits []*domain.CustomerAudit
+		change := func(field string, current *string, value *string) {
+			if value == nil || (current != nil && *current == *value) {
+				return
+			}
+			var old *string
+			if current != nil {
+				old = new(string)
+				*old = *current
+			}
+			changes[field] = *value
+			audits = append(audits, &domain.CustomerAudit{CustomerID: customerID, Field: field, OldValue: old, NewValue: value})
+		}
+		change("phone_number", &customer.PhoneNumber, update.PhoneNumber)
+		change("name", customer.Name, update.Name)
+		change("address", &customer.Address, update.Address)
+		change("postal_code", &customer.PostalCode, update.PostalCode)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if e := tx.Model(customer).Updates(changes).Error; e != nil {
+			return e
+		}
+		if e := tx.Create(&audits).Error; e != nil {
+			return e
+		}
+		return tx.First(&customer, customerID).Error
+	})
+	if e != nil {
+		return nil, errors.ConvertGormErrors(e)
+	}
+	return customer, nil
+}
+
+// GetCustomerAudits returns the changes of the customer, the latest first.
+func (p *Postgres) GetCustomerAudits(ctx context.Context, customerID uint) ([]*domain.CustomerAudit, *errors.AppError) {
+	var audits []*domain.CustomerAudit
+	result := p.db.WithContext(ctx).Where("customer_id = ?", customerID).Order("id DESC").Find(&audits)
+	return audits, errors.ConvertGormErrors(result.Error)
+}
+
+// CreatePhoneVerification replaces the pending phone number change of the customer.
+func (p *Postgres) CreatePhoneVerification(ctx context.Context, customerID uint, phone, codeHash string, ttl time.Duration) (*domain.PhoneVerification, *errors.AppError) {
+	verification := &domain.PhoneVerification{
+		CustomerID:  customerID,
+		PhoneNumber: phone,
+		CodeHash:    codeHash,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	result := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"phone_number", "code_hash", "attempts", "created_at", "expires_at"}),
+	}).Create(verification)
+	return verification, errors.ConvertGormErrors(result.Error)
+}
+
+func (p *Postgres) GetPhoneVerification(ctx context.Context, customerID uint) (*domain.PhoneVerification, *errors.AppError) {
+	var verification *domain.PhoneVerification
+	result := p.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&verification)
+	return verification, errors.ConvertGormErrors(result.Error)
+}
+
+func (p *Postgres) IncrementPhoneVerificationAttempts(ctx context.Context, verificationID uint) *errors.AppError {
+	result := p.db.WithContext(ctx).Model(&domain.PhoneVerification{ID: verificationID}).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return errors.ConvertGormErrors(result.Error)
+}
+
+func (p *Postgres) DeletePhoneVerification(ctx context.Context, verificationID uint) *errors.AppError {
+	result := p.db.WithContext(ctx).Delete(&domain.PhoneVerification{ID: verificationID})
+	return errors.ConvertGormErrors(result.Error)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"math/rand"
+	"time"
+)
+
+// StartIdempotentRequest creates the record for the user and key, unless a record which has not
+// expired exists already. It returns the record and whether it was created by this call.
+func (p *Postgres) StartIdempotentRequest(ctx context.Context, userID uint, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, bool, *errors.AppError) {
+	var (
+		record  *domain.IdempotencyRecord
+		created bool
+	)
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND idempotency_key = ? AND expires_at < NOW()", userID, key).
+			Delete(&domain.IdempotencyRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		record = &domain.IdempotencyRecord{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+			ExpiresAt:      time.Now().Add(ttl),
+		}
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			created = true
+			return nil
+		}
+
+		record = nil
+		return tx.Where(domain.IdempotencyRecord{UserID: userID, IdempotencyKey: key}).First(&record).Error
+	})
+	if e != nil {
+		return nil, false, errors.ConvertGormErrors(e)
+	}
+
+	if created && rand.Intn(100) == 0 {
+		p.db.WithContext(ctx).Where("expires_at < NOW()").Delete(&domain.IdempotencyRecord{})
+	}
+	return record, created, nil
+}
+
+func (p *Postgres) CompleteIdempotentRequest(ctx context.Context, recordID uint, code int, headers map[string]string, body []byte) *errors.AppError {
+	result := p.db.WithContext(ctx).Model(&domain.IdempotencyRecord{ID: recordID}).
+		Updates(domain.IdempotencyRecord{ResponseCode: code, ResponseHeaders: headers, ResponseBody: body})
+	return errors.ConvertGormErrors(result.Error)
+}
+
+func (p *Postgres) DeleteIdempotentRequest(ctx context.Context, recordID uint) *errors.AppError {
+	result := p.db.WithContext(ctx).Delete(&domain.IdempotencyRecord{}, recordID)
+	return errors.ConvertGormErrors(result.Error)
+}
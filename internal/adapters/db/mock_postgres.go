@@ -16,20 +16,35 @@ func NewMockPostgresDB() (*MockPostgres, error) {
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Tehran",
 		configs.DBTestAddress, configs.DBTestUser, configs.DBTestPassword, configs.DBTestName, configs.DBTestPort,
 	)
-	db, e := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, e := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newSlogLogger()})
 	if e != nil {
 		return nil, e
 	}
+	if e = db.Use(tracingPlugin{}); e != nil {
+		return nil, e
+	}
 	pdb := &MockPostgres{Postgres{db: db}}
 	e = pdb.initializeDB()
 	return pdb, e
 }
 
 func (p *MockPostgres) Close() {
+	p.db.Exec(`DROP TABLE order_status_events`)
+	p.db.Exec(`DROP TABLE order_parcels`)
 	p.db.Exec(`DROP TABLE orders`)
+	p.db.Exec(`DROP TABLE addresses`)
+	p.db.Exec(`DROP TABLE quote_options`)
+	p.db.Exec(`DROP TABLE quotes`)
+	p.db.Exec(`DROP TABLE tariffs`)
+	p.db.Exec(`DROP TABLE service_areas`)
 	p.db.Exec(`DROP TABLE customers`)
 	p.db.Exec(`DROP TABLE providers`)
 	p.db.Exec(`DROP TABLE periodic_tasks`)
+	p.db.Exec(`DROP TABLE rate_limit_buckets`)
+	p.db.Exec(`DROP TABLE idempotency_records`)
+	p.db.Exec(`DROP TABLE customer_audits`)
+	p.db.Exec(`DROP TABLE phone_verifications`)
+	p.db.Exec(`DROP TABLE schema_migrations`)
 	if sql, e := p.db.DB(); e == nil {
 		_ = sql.Close()
 	}
@@ -2,30 +2,34 @@ package db
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/common/errors"
 	"sync"
 	"time"
 )
 
+// GetOrder returns the order sent by or to the customer, orders of other customers are not found.
 func (p *Postgres) GetOrder(ctx context.Context, orderID, userID uint) (*domain.Order, *errors.AppError) {
 	var order *domain.Order
 	result := p.db.WithContext(ctx).First(&order, orderID)
 	if result.Error == nil && (order.SenderID != userID && order.ReceiverID != userID) {
-		return nil, nil
+		return nil, errors.NotFoundError(fmt.Errorf("order %d is not sent by or to customer %d", orderID, userID))
 	}
 	return order, errors.ConvertGormErrors(result.Error)
 }
 
-func (p *Postgres) GetOrderWithForeignObjects(ctx context.Context, orderID, senderID uint) (*domain.Order, *errors.AppError) {
-	order, err := p.GetOrder(ctx, orderID, senderID)
+func (p *Postgres) GetOrderWithForeignObjects(ctx context.Context, orderID, userID uint) (*domain.Order, *errors.AppError) {
+	order, err := p.GetOrder(ctx, orderID, userID)
 	if err != nil {
 		return nil, err
 	}
-	errChan := make(chan *errors.AppError, 3)
+	errChan := make(chan *errors.AppError, 4)
 	wg := sync.WaitGroup{}
-	wg.Add(3)
+	wg.Add(4)
 
 	go func() {
 		defer wg.Done()
@@ -57,6 +61,16 @@ func (p *Postgres) GetOrderWithForeignObjects(ctx context.Context, orderID, send
 		order.Receiver = receiver
 	}()
 
+	go func() {
+		defer wg.Done()
+		parcels, e := p.GetOrderParcels(ctx, order.ID)
+		if e != nil {
+			errChan <- e
+			return
+		}
+		order.Parcels = parcels
+	}()
+
 	wg.Wait()
 	close(errChan)
 
@@ -68,15 +82,118 @@ func (p *Postgres) GetOrderWithForeignObjects(ctx context.Context, orderID, send
 	return order, nil
 }
 
-func (p *Postgres) CreateOrder(ctx context.Context, userID, receiverID, providerID uint, product *string) (*domain.Order, *errors.AppError) {
-	order := &domain.Order{
-		ProviderID: providerID,
-		SenderID:   userID,
-		ReceiverID: receiverID,
-		Product:    product,
+// CreateOrder creates the order with the current contacts and addresses of the sender and the receiver,
+// so later changes of their profiles and addresses do not change the order. The saved addresses referenced
+// by the order are used, or the default addresses of the customers when none is referenced. Orders with
+// postal codes outside the service areas of the provider, or other than the ones of their quote, are rejected.
+// The order is given its tracking code, and its parcels are created with it and given their tracking numbers,
+// orders without parcels get one. The history of the order starts with its status.
+func (p *Postgres) CreateOrder(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError) {
+	if len(order.Parcels) == 0 {
+		order.SetParcels(nil)
 	}
-	result := p.db.WithContext(ctx).Create(&order)
-	return order, errors.ConvertGormErrors(result.Error)
+	trackingCode, e := domain.NewTrackingNumber()
+	if e != nil {
+		return nil, errors.InternalServerError(e)
+	}
+	order.TrackingCode = trackingCode
+	for _, parcel := range order.Parcels {
+		trackingNumber, e := domain.NewTrackingNumber()
+		if e != nil {
+			return nil, errors.InternalServerError(e)
+		}
+		parcel.TrackingNumber = trackingNumber
+	}
+	var uncovered map[string]string
+	e = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		e := setParties(tx, order)
+		if e != nil {
+			return e
+		}
+		if uncovered, e = uncoveredPostalCodes(tx, order); e != nil {
+			return e
+		}
+		if len(uncovered) > 0 {
+			return errNotCovered
+		}
+		if e = checkQuote(tx, order); e != nil {
+			return e
+		}
+		if e = tx.Create(&order).Error; e != nil {
+			return e
+		}
+		event := &domain.OrderStatusEvent{OrderID: order.ID, Status: order.Status}
+		order.History = []*domain.OrderStatusEvent{event}
+		return tx.Create(event).Error
+	})
+	switch {
+	case stdErrors.Is(e, errNotCovered):
+		return nil, errors.NotCovered(uncovered)
+	case stdErrors.Is(e, errQuoteMismatch):
+		return nil, errors.UnprocessableEntity(errors.CodeQuoteMismatch, "the quote was made for other postal codes")
+	}
+	return order, errors.ConvertGormErrors(e)
+}
+
+// PreviewOrder fills the contacts and the addresses of the sender and the receiver in the order like CreateOrder,
+// without creating it.
+func (p *Postgres) PreviewOrder(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError) {
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return setParties(tx, order)
+	})
+	return order, errors.ConvertGormErrors(e)
+}
+
+// setParties copies the contacts and the addresses of the sender and the receiver to the order.
+func setParties(tx *gorm.DB, order *domain.Order) error {
+	var customers []*domain.Customer
+	if e := tx.Clauses(clause.Locking{Strength: "SHARE"}).Find(&customers, []uint{order.SenderID, order.ReceiverID}).Error; e != nil {
+		return e
+	}
+	for _, customer := range customers {
+		if customer.ID == order.SenderID {
+			pickup, e := addressOf(tx, customer, order.PickupAddressID)
+			if e != nil {
+				return e
+			}
+			order.SetPickup(customer, pickup)
+		}
+		if customer.ID == order.ReceiverID {
+			dropoff, e := addressOf(tx, customer, order.DropoffAddressID)
+			if e != nil {
+				return e
+			}
+			order.SetDropoff(customer, dropoff)
+		}
+	}
+	return nil
+}
+
+// GetProvidersOnTimeRate returns the share of the orders of the past 30 days delivered within the days
+// after their pickup, for the providers having delivered orders.
+func (p *Postgres) GetProvidersOnTimeRate(ctx context.Context, days int) ([]*domain.ProviderOnTimeRate, *errors.AppError) {
+	var data []*domain.ProviderOnTimeRate
+	result := p.db.WithContext(ctx).Raw(`
+		select provider_id, count(*) as delivered,
+			avg(case when delivery_date - picked_up_date <= ? then 1.0 else 0.0 end) as on_time_rate
+			from orders
+			where delivery_date is not null
+			and picked_up_date is not null
+			and created_at >= NOW() - INTERVAL '30 days'
+			group by provider_id
+	`, days).Scan(&data)
+	return data, errors.ConvertGormErrors(result.Error)
+}
+
+// GetProvidersLoad returns the number of orders created since the time, for the providers having any.
+func (p *Postgres) GetProvidersLoad(ctx context.Context, since time.Time) ([]*domain.ProviderLoad, *errors.AppError) {
+	var data []*domain.ProviderLoad
+	result := p.db.WithContext(ctx).Model(&domain.Order{}).
+		Select("provider_id, count(*) as orders").
+		Where("created_at >= ?", since).
+		Group("provider_id").
+		Scan(&data)
+	return data, errors.ConvertGormErrors(result.Error)
 }
 
 func (p *Postgres) GetOngoingOrders(ctx context.Context) ([]*domain.Order, *errors.AppError) {
@@ -85,40 +202,162 @@ func (p *Postgres) GetOngoingOrders(ctx context.Context) ([]*domain.Order, *erro
 	return orders, errors.ConvertGormErrors(result.Error)
 }
 
+// GetUnbookedOrders returns the orders created before the time whose shipment is not booked yet, with their parcels,
+// as long as they are not picked up.
+func (p *Postgres) GetUnbookedOrders(ctx context.Context, createdBefore time.Time) ([]*domain.Order, *errors.AppError) {
+	var orders []*domain.Order
+	result := p.db.WithContext(ctx).Preload("Parcels", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Where("booking_status = ? AND status IN ?", domain.GetOrderBookingStatus().Pending, domain.GetUnpickedOrderStatus()).
+		Where("created_at < ?", createdBefore).
+		Order("id").Find(&orders)
+	return orders, errors.ConvertGormErrors(result.Error)
+}
+
+// UpdateOrderBooking saves the booking status of the order and the number of attempts to book it.
+func (p *Postgres) UpdateOrderBooking(ctx context.Context, orderID uint, status string, attempts uint) *errors.AppError {
+	result := p.db.WithContext(ctx).Model(&domain.Order{ID: orderID}).
+		Updates(map[string]any{"booking_status": status, "booking_attempts": attempts})
+	return errors.ConvertGormErrors(result.Error)
+}
+
+// GetOrderParcels returns the parcels of the order by their number.
+func (p *Postgres) GetOrderParcels(ctx context.Context, orderID uint) ([]*domain.OrderParcel, *errors.AppError) {
+	var parcels []*domain.OrderParcel
+	result := p.db.WithContext(ctx).Where("order_id = ?", orderID).Order("number").Find(&parcels)
+	return parcels, errors.ConvertGormErrors(result.Error)
+}
+
+// UpdateOrderStatus sets the status of every parcel of the order, and returns the order with its parcels.
 func (p *Postgres) UpdateOrderStatus(ctx context.Context, orderID uint, status string) (*domain.Order, *errors.AppError) {
 	var order *domain.Order
-	result := p.db.WithContext(ctx).First(&order, orderID)
-	if result.Error != nil {
-		return nil, errors.ConvertGormErrors(result.Error)
-	}
-	if order.Status == status {
-		return order, nil
-	}
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := updateParcelsStatus(tx.Where("order_id = ?", orderID), status); e != nil {
+			return e
+		}
+		var e error
+		order, e = deriveOrderStatus(tx, orderID)
+		return e
+	})
+	return order, errors.ConvertGormErrors(e)
+}
 
-	result = p.db.WithContext(ctx).WithContext(ctx).Model(&order).
-		Where(domain.Order{ID: orderID}).
-		Updates(domain.Order{Status: status})
+// UpdateParcelStatus sets the status of the parcel, and returns its order with the status derived from its parcels.
+func (p *Postgres) UpdateParcelStatus(ctx context.Context, parcelID uint, status string) (*domain.Order, *errors.AppError) {
+	var order *domain.Order
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var parcel *domain.OrderParcel
+		if e := tx.First(&parcel, parcelID).Error; e != nil {
+			return e
+		}
+		if e := updateParcelsStatus(tx.Where("id = ?", parcelID), status); e != nil {
+			return e
+		}
+		var e error
+		order, e = deriveOrderStatus(tx, parcel.OrderID)
+		return e
+	})
+	return order, errors.ConvertGormErrors(e)
+}
 
+// updateParcelsStatus sets the status of the parcels other than the ones already in it, with the date of
+// their pickup or their delivery.
+func updateParcelsStatus(tx *gorm.DB, status string) error {
+	update := domain.OrderParcel{Status: status}
 	if status == domain.GetOrderStatus().PickedUp {
 		// used these two lines to simulate
 		//pastDays := 2 + rand.Intn(3)
 		//pickedUpDate := time.Now().AddDate(0, 0, -1*pastDays)
 		pickedUpDate := time.Now()
-		result = result.Updates(domain.Order{PickedUpDate: &pickedUpDate})
-		order.PickedUpDate = &pickedUpDate
+		update.PickedUpDate = &pickedUpDate
 	} else if status == domain.GetOrderStatus().Delivered {
 		// used these two lines to simulate
 		//pastDays := rand.Intn(3)
 		//deliveryDate := time.Now().AddDate(0, 0, -1*pastDays)
 		deliveryDate := time.Now()
-		result = result.Updates(domain.Order{DeliveryDate: &deliveryDate})
-		order.DeliveryDate = &deliveryDate
+		update.DeliveryDate = &deliveryDate
 	}
+	return tx.Model(&domain.OrderParcel{}).Where("status <> ?", status).Updates(update).Error
+}
 
-	order.Status = status
+// deriveOrderStatus sets the status and the dates of the order from its parcels.
+func deriveOrderStatus(tx *gorm.DB, orderID uint) (*domain.Order, error) {
+	var order *domain.Order
+	if e := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; e != nil {
+		return nil, e
+	}
+	if e := tx.Where("order_id = ?", orderID).Order("number").Find(&order.Parcels).Error; e != nil {
+		return nil, e
+	}
+	previous := order.Status
+	order.DeriveStatus()
+	e := tx.Model(&domain.Order{ID: orderID}).
+		Select("status", "picked_up_date", "delivery_date").
+		Updates(order).Error
+	if e == nil && order.Status != previous {
+		e = tx.Create(&domain.OrderStatusEvent{OrderID: orderID, Status: order.Status}).Error
+	}
+	return order, e
+}
+
+// GetTrackedOrder returns the order with the tracking code, with its parcels and its history.
+func (p *Postgres) GetTrackedOrder(ctx context.Context, trackingCode string) (*domain.Order, *errors.AppError) {
+	var order *domain.Order
+	result := p.db.WithContext(ctx).
+		Preload("Parcels", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Where("tracking_code = ?", trackingCode).
+		First(&order)
 	return order, errors.ConvertGormErrors(result.Error)
 }
 
+// ListIncomingOrders returns a page of the orders sent to the receiver with their parcels, the latest first.
+func (p *Postgres) ListIncomingOrders(ctx context.Context, receiverID uint, request *domain.OrderListRequest) ([]*domain.Order, int64, *errors.AppError) {
+	query := p.db.WithContext(ctx).Model(&domain.Order{}).Where("receiver_id = ?", receiverID)
+	if request.Status != nil {
+		query = query.Where("status = ?", *request.Status)
+	}
+
+	var total int64
+	if result := query.Session(&gorm.Session{}).Count(&total); result.Error != nil {
+		return nil, 0, errors.ConvertGormErrors(result.Error)
+	}
+	var orders []*domain.Order
+	result := query.Preload("Parcels", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Order("created_at DESC, id DESC").Limit(int(request.Limit)).Offset(int(request.Offset)).Find(&orders)
+	return orders, total, errors.ConvertGormErrors(result.Error)
+}
+
+// UpdateOrderDelivery saves the delivery of the order as long as the order is not picked up, in a single statement
+// so it cannot be picked up between the check and the save. Orders picked up in the meantime are a conflict.
+func (p *Postgres) UpdateOrderDelivery(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError) {
+	result := p.db.WithContext(ctx).Model(order).
+		Where("status IN ?", domain.GetUnpickedOrderStatus()).
+		Updates(deliveryChanges(order))
+	if result.Error != nil {
+		return nil, errors.ConvertGormErrors(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.Conflict(errors.CodeOrderPickedUp, "the order is picked up, its delivery cannot be changed")
+	}
+	return order, nil
+}
+
+// RevertOrderDelivery saves the previous delivery of the order back whatever its status, once the provider
+// rejected the change saved by UpdateOrderDelivery.
+func (p *Postgres) RevertOrderDelivery(ctx context.Context, order *domain.Order) *errors.AppError {
+	result := p.db.WithContext(ctx).Model(order).Updates(deliveryChanges(order))
+	return errors.ConvertGormErrors(result.Error)
+}
+
+func deliveryChanges(order *domain.Order) map[string]any {
+	return map[string]any{
+		"delivery_slot_start":   order.DeliverySlotStart,
+		"delivery_slot_end":     order.DeliverySlotEnd,
+		"hold_at_location":      order.HoldAtLocation,
+		"delivery_instructions": order.DeliveryInstructions,
+	}
+}
+
 func (p *Postgres) UpdateOrderNotification(ctx context.Context, orderID uint) *errors.AppError {
 	var order *domain.Order
 	result := p.db.WithContext(ctx).WithContext(ctx).Model(&order).
@@ -127,6 +366,33 @@ func (p *Postgres) UpdateOrderNotification(ctx context.Context, orderID uint) *e
 	return errors.ConvertGormErrors(result.Error)
 }
 
+// GetDeliverySamples returns up to the limit of the latest orders of the provider delivered since the time, which
+// orders of the provider are estimated by.
+func (p *Postgres) GetDeliverySamples(ctx context.Context, providerID uint, since time.Time, limit int) ([]*domain.DeliverySample, *errors.AppError) {
+	var samples []*domain.DeliverySample
+	result := p.db.WithContext(ctx).Model(&domain.Order{}).
+		Select("pickup_postal_code, dropoff_postal_code, created_at, picked_up_date, delivery_date").
+		Where("provider_id = ? AND status = ?", providerID, domain.GetOrderStatus().Delivered).
+		Where("picked_up_date IS NOT NULL AND delivery_date >= ?", since).
+		Order("delivery_date DESC, id DESC").Limit(limit).
+		Scan(&samples)
+	return samples, errors.ConvertGormErrors(result.Error)
+}
+
+// UpdateOrderEstimate saves the delivery estimate of the order.
+func (p *Postgres) UpdateOrderEstimate(ctx context.Context, orderID uint, estimate *domain.DeliveryEstimate) *errors.AppError {
+	result := p.db.WithContext(ctx).Model(&domain.Order{ID: orderID}).
+		Updates(map[string]any{
+			"estimated_delivery_date":       estimate.Date,
+			"estimated_delivery_earliest":   estimate.Earliest,
+			"estimated_delivery_latest":     estimate.Latest,
+			"estimated_delivery_basis":      estimate.Basis,
+			"estimated_delivery_by_weekday": estimate.ByWeekday,
+			"estimated_delivery_samples":    estimate.Samples,
+		})
+	return errors.ConvertGormErrors(result.Error)
+}
+
 func (p *Postgres) GetProvidersMeanDeliveryTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError) {
 	var data []*domain.ProviderByDeliveryTime
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
@@ -12,6 +13,9 @@ import (
 	"time"
 )
 
+// schemaVersion must be increased whenever initializeDB changes the schema.
+const schemaVersion = 18
+
 type Postgres struct {
 	db *gorm.DB
 }
@@ -21,10 +25,13 @@ func NewPostgresDB() (*Postgres, error) {
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Tehran",
 		configs.DBAddress, configs.DBUser, configs.DBPassword, configs.DBName, configs.DBPort,
 	)
-	db, e := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, e := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newSlogLogger()})
 	if e != nil {
 		return nil, e
 	}
+	if e = db.Use(tracingPlugin{}); e != nil {
+		return nil, e
+	}
 	pdb := &Postgres{db: db}
 	e = pdb.initializeDB()
 	return pdb, e
@@ -35,14 +42,131 @@ func (p *Postgres) initializeDB() error {
 	if e != nil {
 		return e
 	}
+	verifyProviders := p.db.Migrator().HasTable(&domain.Provider{}) && !p.db.Migrator().HasColumn(&domain.Provider{}, "VerificationStatus")
 	e = p.db.AutoMigrate(&domain.Provider{})
 	if e != nil {
 		return e
 	}
-	e = p.db.AutoMigrate(&domain.Order{})
+	if verifyProviders {
+		// providers registered before the verification keep taking orders
+		e = p.db.Model(&domain.Provider{}).Where("true").
+			Update("verification_status", domain.GetProviderVerificationStatus().Verified).Error
+		if e != nil {
+			return e
+		}
+	}
+	e = p.db.AutoMigrate(&domain.ServiceArea{})
 	if e != nil {
 		return e
 	}
+	chargeQuotes := p.db.Migrator().HasTable(&domain.Quote{}) && !p.db.Migrator().HasColumn(&domain.Quote{}, "parcel_chargeable_weight")
+	e = p.db.AutoMigrate(&domain.Tariff{}, &domain.Quote{}, &domain.QuoteOption{})
+	if e != nil {
+		return e
+	}
+	if chargeQuotes {
+		// quotes made before parcels had a chargeable weight are charged by their weight in kilograms and
+		// dimensions in centimeters, like the parcels of orders checked against them
+		e = p.db.Exec(`
+      UPDATE quotes SET parcel_chargeable_weight =
+        ceil(greatest(parcel_weight, parcel_length * parcel_width * parcel_height / ?) * 1000) / 1000;
+    `, domain.VolumetricDivisor).Error
+		if e != nil {
+			return e
+		}
+	}
+	e = p.db.AutoMigrate(&domain.Address{})
+	if e != nil {
+		return e
+	}
+	snapshotAddresses := p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasColumn(&domain.Order{}, "PickupAddress")
+	snapshotContacts := p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasColumn(&domain.Order{}, "PickupContactPhone")
+	splitParcels := p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasTable(&domain.OrderParcel{})
+	startHistory := p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasTable(&domain.OrderStatusEvent{})
+	keepBookings := p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasColumn(&domain.Order{}, "BookingStatus")
+	if p.db.Migrator().HasTable(&domain.Order{}) && !p.db.Migrator().HasColumn(&domain.Order{}, "TrackingCode") {
+		// orders created before they had tracking codes get random ones
+		e = p.db.Exec(`
+      ALTER TABLE orders ADD COLUMN tracking_code varchar(12);
+      UPDATE orders SET tracking_code = upper(substr(md5(random()::text || id::text), 1, 12));
+      ALTER TABLE orders ALTER COLUMN tracking_code SET NOT NULL;
+    `).Error
+		if e != nil {
+			return e
+		}
+	}
+	e = p.db.AutoMigrate(&domain.Order{}, &domain.OrderParcel{}, &domain.OrderStatusEvent{})
+	if e != nil {
+		return e
+	}
+	if snapshotAddresses {
+		// orders created before the addresses were kept with them take the current addresses of the customers
+		e = p.db.Exec(`
+      UPDATE orders SET
+        pickup_address = s.address, pickup_postal_code = s.postal_code,
+        dropoff_address = r.address, dropoff_postal_code = r.postal_code
+      FROM customers s, customers r
+      WHERE orders.sender_id = s.id AND orders.receiver_id = r.id;
+    `).Error
+		if e != nil {
+			return e
+		}
+	}
+	if snapshotContacts {
+		// orders created before the contacts were kept with them take the current contacts of the customers
+		e = p.db.Exec(`
+      UPDATE orders SET
+        pickup_contact_name = s.name, pickup_contact_phone = s.phone_number,
+        dropoff_contact_name = r.name, dropoff_contact_phone = r.phone_number
+      FROM customers s, customers r
+      WHERE orders.sender_id = s.id AND orders.receiver_id = r.id;
+    `).Error
+		if e != nil {
+			return e
+		}
+	}
+
+	if splitParcels {
+		// orders created before they had parcels get a parcel with the parcel, the status and the dates of the order
+		e = p.db.Exec(`
+      INSERT INTO order_parcels (order_id, number, tracking_number, weight, weight_unit, length, width, height,
+        dimension_unit, declared_value, pieces, fragile, hazardous, chargeable_weight,
+        status, picked_up_date, delivery_date, created_at, updated_at)
+      SELECT id, 1, upper(substr(md5(random()::text || id::text), 1, 12)), parcel_weight, parcel_weight_unit,
+        parcel_length, parcel_width, parcel_height, parcel_dimension_unit, parcel_declared_value, parcel_pieces,
+        parcel_fragile, parcel_hazardous, parcel_chargeable_weight,
+        status, picked_up_date, delivery_date, created_at, updated_at
+      FROM orders;
+    `).Error
+		if e != nil {
+			return e
+		}
+		// the ongoing statuses took PARTIALLY_DELIVERED
+		e = p.db.Exec(`DROP INDEX IF EXISTS idx_ongoing_status;`).Error
+		if e != nil {
+			return e
+		}
+	}
+
+	if startHistory {
+		// the history of orders created before it was kept starts with their current status
+		e = p.db.Exec(`
+      INSERT INTO order_status_events (order_id, status, created_at)
+      SELECT id, status, updated_at FROM orders;
+    `).Error
+		if e != nil {
+			return e
+		}
+	}
+
+	if keepBookings {
+		// shipments of the orders created before the bookings were kept are not booked again
+		e = p.db.Model(&domain.Order{}).Where("true").
+			Update("booking_status", domain.GetOrderBookingStatus().Booked).Error
+		if e != nil {
+			return e
+		}
+	}
 
 	if !p.db.Migrator().HasIndex(&domain.Order{}, "idx_ongoing_status") {
 		statusList := strings.Join(domain.GetOngoingOrderStatus(), "', '")
@@ -54,7 +178,32 @@ func (p *Postgres) initializeDB() error {
 	}
 
 	e = p.db.AutoMigrate(&domain.PeriodicTask{})
-	return e
+	if e != nil {
+		return e
+	}
+
+	e = p.db.AutoMigrate(&domain.RateLimitBucket{})
+	if e != nil {
+		return e
+	}
+
+	e = p.db.AutoMigrate(&domain.IdempotencyRecord{})
+	if e != nil {
+		return e
+	}
+
+	e = p.db.AutoMigrate(&domain.CustomerAudit{}, &domain.PhoneVerification{})
+	if e != nil {
+		return e
+	}
+
+	e = p.db.AutoMigrate(&domain.SchemaMigration{})
+	if e != nil {
+		return e
+	}
+	result := p.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.SchemaMigration{Version: schemaVersion, AppliedAt: time.Now()})
+	return result.Error
 }
 
 func (p *Postgres) Close() {
@@ -64,15 +213,23 @@ func (p *Postgres) Close() {
 	return
 }
 
-func (p *Postgres) Ready() bool {
-	if sql, e := p.db.DB(); e == nil {
-		e = sql.Ping()
-		if e != nil {
-			return false
-		}
-		return true
+func (p *Postgres) Ping(ctx context.Context) *errors.AppError {
+	sql, e := p.db.DB()
+	if e != nil {
+		return errors.InternalServerError(e)
 	}
-	return false
+	if e = sql.PingContext(ctx); e != nil {
+		return errors.InternalServerError(e)
+	}
+	return nil
+}
+
+func (p *Postgres) GetMigrationVersion(ctx context.Context) (*domain.MigrationVersion, *errors.AppError) {
+	version := &domain.MigrationVersion{Expected: schemaVersion}
+	result := p.db.WithContext(ctx).Model(&domain.SchemaMigration{}).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version.Applied)
+	return version, errors.ConvertGormErrors(result.Error)
 }
 
 func (p *Postgres) GetAllProviders(ctx context.Context) ([]*domain.Provider, *errors.AppError) {
@@ -81,21 +238,94 @@ func (p *Postgres) GetAllProviders(ctx context.Context) ([]*domain.Provider, *er
 	return providers, errors.ConvertGormErrors(result.Error)
 }
 
+// ListProviders returns a page of the providers, serving the postal codes of the request when given.
+func (p *Postgres) ListProviders(ctx context.Context, request *domain.ProviderListRequest) ([]*domain.Provider, int64, *errors.AppError) {
+	query := p.db.WithContext(ctx).Model(&domain.Provider{})
+	for _, postalCode := range []*string{request.From, request.To} {
+		if postalCode != nil {
+			query = query.Where(coverageQuery, map[string]any{"code": *postalCode})
+		}
+	}
+
+	var total int64
+	if result := query.Session(&gorm.Session{}).Count(&total); result.Error != nil {
+		return nil, 0, errors.ConvertGormErrors(result.Error)
+	}
+	if request.Limit > 0 {
+		query = query.Limit(int(request.Limit))
+	}
+	var providers []*domain.Provider
+	result := query.Order("id").Offset(int(request.Offset)).Find(&providers)
+	return providers, total, errors.ConvertGormErrors(result.Error)
+}
+
 func (p *Postgres) GetProvider(ctx context.Context, providerID uint) (*domain.Provider, *errors.AppError) {
 	var provider *domain.Provider
 	result := p.db.WithContext(ctx).First(&provider, providerID)
 	return provider, errors.ConvertGormErrors(result.Error)
 }
 
-func (p *Postgres) CreateProvider(ctx context.Context, name, url *string) (*domain.Provider, *errors.AppError) {
+func (p *Postgres) CreateProvider(ctx context.Context, name, url, quoteUrl *string) (*domain.Provider, *errors.AppError) {
 	provider := &domain.Provider{
-		Name: *name,
-		Url:  *url,
+		Name:     *name,
+		Url:      *url,
+		QuoteUrl: quoteUrl,
 	}
 	result := p.db.WithContext(ctx).Create(&provider)
 	return provider, errors.ConvertGormErrors(result.Error)
 }
 
+// UpdateProvider applies the given fields to the provider.
+func (p *Postgres) UpdateProvider(ctx context.Context, update *domain.ProviderUpdateRequest) (*domain.Provider, *errors.AppError) {
+	var provider *domain.Provider
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&provider, update.ProviderID).Error; e != nil {
+			return e
+		}
+		changes := make(map[string]any)
+		if update.Name != nil {
+			changes["name"] = *update.Name
+		}
+		if update.Url != nil && *update.Url != provider.Url {
+			// a new url has to be verified again
+			changes["url"] = *update.Url
+			changes["verification_status"] = domain.GetProviderVerificationStatus().Pending
+		}
+		if update.Active != nil {
+			changes["active"] = *update.Active
+		}
+		if update.QuoteUrl.Set {
+			changes["quote_url"] = update.QuoteUrl.Value
+		}
+		if update.SelectionWeight != nil {
+			changes["selection_weight"] = *update.SelectionWeight
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Model(provider).Clauses(clause.Returning{}).Updates(changes).Error
+	})
+	if e != nil {
+		return nil, errors.ConvertGormErrors(e)
+	}
+	return provider, nil
+}
+
+// UpdateProviderVerification stores the result of a verification of the provider, as long as its url is still the
+// probed one. A provider whose url changed during the probe is not found, its new url is verified on its own.
+func (p *Postgres) UpdateProviderVerification(ctx context.Context, providerID uint, url, status string, detail *string) (*domain.Provider, *errors.AppError) {
+	provider := &domain.Provider{ID: providerID}
+	result := p.db.WithContext(ctx).Model(provider).Clauses(clause.Returning{}).Where("url = ?", url).Updates(map[string]any{
+		"verification_status":     status,
+		"verification_detail":     detail,
+		"verification_checked_at": time.Now(),
+	})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = gorm.ErrRecordNotFound
+	}
+	return provider, errors.ConvertGormErrors(result.Error)
+}
+
 func (p *Postgres) GetCustomer(ctx context.Context, userID uint) (*domain.Customer, *errors.AppError) {
 	var customer *domain.Customer
 	result := p.db.WithContext(ctx).First(&customer, userID)
@@ -116,18 +346,28 @@ func (p *Postgres) CreateCustomer(ctx context.Context, name, phone, addr, postal
 func (p *Postgres) CreateOrUpdatePeriodicTask(ctx context.Context, name string, interval int, failed bool, e *string) (*domain.PeriodicTask, *errors.AppError) {
 	var task *domain.PeriodicTask
 	t := time.Now()
+	attrs := map[string]any{
+		"interval_in_minute": uint(interval),
+		"last_run_time":      &t,
+		"failed":             failed,
+		"error":              e,
+	}
+	if !failed {
+		attrs["last_success_time"] = &t
+	}
 	result := p.db.WithContext(ctx).
 		Where(domain.PeriodicTask{JobName: name}).
-		Assign(domain.PeriodicTask{
-			IntervalInMinute: uint(interval),
-			LastRunTime:      &t,
-			Failed:           failed,
-			Error:            e,
-		}).
+		Assign(attrs).
 		FirstOrCreate(&task)
 	return task, errors.ConvertGormErrors(result.Error)
 }
 
+func (p *Postgres) GetPeriodicTask(ctx context.Context, name string) (*domain.PeriodicTask, *errors.AppError) {
+	var task *domain.PeriodicTask
+	result := p.db.WithContext(ctx).Where(domain.PeriodicTask{JobName: name}).First(&task)
+	return task, errors.ConvertGormErrors(result.Error)
+}
+
 func (p *Postgres) GetOrCreatePeriodicTask(ctx context.Context, name string, interval int) (*domain.PeriodicTask, *errors.AppError) {
 	var task *domain.PeriodicTask
 	result := p.db.WithContext(ctx).
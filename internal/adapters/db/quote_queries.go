@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	stdErrors "errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+)
+
+// errQuoteMismatch rolls back the creation of an order with other postal codes than the ones of its quote.
+var errQuoteMismatch = stdErrors.New("postal codes of the order differ from its quote")
+
+func (p *Postgres) ListTariffs(ctx context.Context, providerID uint) ([]*domain.Tariff, *errors.AppError) {
+	if _, err := p.GetProvider(ctx, providerID); err != nil {
+		return nil, err
+	}
+	var tariffs []*domain.Tariff
+	result := p.db.WithContext(ctx).Where("provider_id = ?", providerID).Order("max_weight").Find(&tariffs)
+	return tariffs, errors.ConvertGormErrors(result.Error)
+}
+
+// ReplaceTariffs replaces the tariffs of the provider with the given ones.
+func (p *Postgres) ReplaceTariffs(ctx context.Context, providerID uint, tariffs []*domain.Tariff) ([]*domain.Tariff, *errors.AppError) {
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&domain.Provider{}, providerID).Error; e != nil {
+			return e
+		}
+		if e := tx.Where("provider_id = ?", providerID).Delete(&domain.Tariff{}).Error; e != nil {
+			return e
+		}
+		if len(tariffs) == 0 {
+			return nil
+		}
+		for _, tariff := range tariffs {
+			tariff.ProviderID = providerID
+		}
+		return tx.Create(&tariffs).Error
+	})
+	if e != nil {
+		return nil, errors.ConvertGormErrors(e)
+	}
+	return tariffs, nil
+}
+
+// GetQuotableProviders returns the providers taking orders which serve both postal codes.
+func (p *Postgres) GetQuotableProviders(ctx context.Context, pickupPostalCode, dropoffPostalCode string) ([]*domain.Provider, *errors.AppError) {
+	var providers []*domain.Provider
+	result := p.db.WithContext(ctx).
+		Where("active AND verification_status = ?", domain.GetProviderVerificationStatus().Verified).
+		Where(coverageQuery, map[string]any{"code": pickupPostalCode}).
+		Where(coverageQuery, map[string]any{"code": dropoffPostalCode}).
+		Order("id").
+		Find(&providers)
+	return providers, errors.ConvertGormErrors(result.Error)
+}
+
+// CreateQuote saves the quote along with its options.
+func (p *Postgres) CreateQuote(ctx context.Context, quote *domain.Quote) (*domain.Quote, *errors.AppError) {
+	result := p.db.WithContext(ctx).Create(&quote)
+	return quote, errors.ConvertGormErrors(result.Error)
+}
+
+// GetQuote returns the quote with its options when it belongs to the customer.
+func (p *Postgres) GetQuote(ctx context.Context, quoteID, customerID uint) (*domain.Quote, *errors.AppError) {
+	var quote *domain.Quote
+	result := p.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("rank") }).
+		Where("customer_id = ?", customerID).
+		First(&quote, quoteID)
+	return quote, errors.ConvertGormErrors(result.Error)
+}
+
+// checkQuote makes sure the order is sent between the postal codes its quote was made for.
+func checkQuote(tx *gorm.DB, order *domain.Order) error {
+	if order.QuoteID == nil {
+		return nil
+	}
+	var quote *domain.Quote
+	if e := tx.First(&quote, *order.QuoteID).Error; e != nil {
+		return e
+	}
+	if quote.PickupPostalCode != order.PickupPostalCode || quote.DropoffPostalCode != order.DropoffPostalCode {
+		return errQuoteMismatch
+	}
+	return nil
+}
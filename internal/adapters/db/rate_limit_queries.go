@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"math/rand"
+)
+
+// TakeToken refills and takes a token from the bucket in a single statement,
+// so that replicas sharing the database share the same limits.
+func (p *Postgres) TakeToken(ctx context.Context, key string, limit *domain.RateLimit) (*domain.RateLimitResult, *errors.AppError) {
+	var bucket domain.RateLimitBucket
+	result := p.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+			VALUES (@key, @capacity - 1, true, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			allowed = LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * @rate) >= 1,
+			tokens = LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * @rate)
+				- CASE WHEN LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * @rate) >= 1 THEN 1 ELSE 0 END,
+			updated_at = NOW()
+		RETURNING key, tokens, allowed, updated_at
+	`, map[string]any{
+		"key":      key,
+		"capacity": float64(limit.Requests),
+		"rate":     limit.RefillRate(),
+	}).Scan(&bucket)
+	if result.Error != nil {
+		return nil, errors.ConvertGormErrors(result.Error)
+	}
+
+	// idle buckets are full again, so they can be dropped once in a while
+	if rand.Intn(100) == 0 {
+		p.db.WithContext(ctx).Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - INTERVAL '1 day'`)
+	}
+	return limit.Result(bucket.Allowed, bucket.Tokens), nil
+}
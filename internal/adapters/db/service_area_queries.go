@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	stdErrors "errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+)
+
+// coverageQuery keeps the providers serving the postal code named code. Providers without
+// service areas serve every postal code, otherwise the prefix of the postal code of the length of the
+// bounds of one of their areas must be within the area.
+const coverageQuery = `
+  NOT EXISTS (SELECT 1 FROM service_areas a WHERE a.provider_id = providers.id)
+  OR EXISTS (
+    SELECT 1 FROM service_areas a
+    WHERE a.provider_id = providers.id
+      AND LENGTH(@code) >= LENGTH(a.postal_code_from)
+      AND LEFT(@code, LENGTH(a.postal_code_from)) BETWEEN a.postal_code_from AND a.postal_code_to
+  )`
+
+func (p *Postgres) ListServiceAreas(ctx context.Context, providerID uint) ([]*domain.ServiceArea, *errors.AppError) {
+	if _, err := p.GetProvider(ctx, providerID); err != nil {
+		return nil, err
+	}
+	var areas []*domain.ServiceArea
+	result := p.db.WithContext(ctx).Where("provider_id = ?", providerID).Order("postal_code_from, id").Find(&areas)
+	return areas, errors.ConvertGormErrors(result.Error)
+}
+
+// ImportServiceAreas adds the areas to the provider, or replaces its areas with them, and returns
+// every area of the provider.
+func (p *Postgres) ImportServiceAreas(ctx context.Context, providerID uint, areas []*domain.ServiceArea, replace bool) ([]*domain.ServiceArea, *errors.AppError) {
+	var all []*domain.ServiceArea
+	e := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&domain.Provider{}, providerID).Error; e != nil {
+			return e
+		}
+		if replace {
+			if e := tx.Where("provider_id = ?", providerID).Delete(&domain.ServiceArea{}).Error; e != nil {
+				return e
+			}
+		}
+		for _, area := range areas {
+			area.ProviderID = providerID
+		}
+		if e := tx.CreateInBatches(areas, 500).Error; e != nil {
+			return e
+		}
+		return tx.Where("provider_id = ?", providerID).Order("postal_code_from, id").Find(&all).Error
+	})
+	if e != nil {
+		return nil, errors.ConvertGormErrors(e)
+	}
+	return all, nil
+}
+
+// errNotCovered rolls back the creation of an order with postal codes the provider does not serve.
+var errNotCovered = stdErrors.New("postal codes not covered by the provider")
+
+// uncoveredPostalCodes returns the pickup and dropoff postal codes of the order which the provider
+// does not serve, keyed by their field.
+func uncoveredPostalCodes(tx *gorm.DB, order *domain.Order) (map[string]string, error) {
+	uncovered := make(map[string]string)
+	for field, postalCode := range map[string]string{
+		"pickup_postal_code":  order.PickupPostalCode,
+		"dropoff_postal_code": order.DropoffPostalCode,
+	} {
+		var count int64
+		e := tx.Model(&domain.Provider{}).
+			Where("id = ?", order.ProviderID).
+			Where(coverageQuery, map[string]any{"code": postalCode}).
+			Count(&count).Error
+		if e != nil {
+			return nil, e
+		}
+		if count == 0 {
+			uncovered[field] = postalCode + " is not served by the provider"
+		}
+	}
+	return uncovered, nil
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"net/http"
+	"testing"
+)
+
+func TestPostgres_CreateAddress(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	customer, _, _ := setUpOrderForeignObjects(t)
+
+	t.Run("first address is the default", func(t *testing.T) {
+		address, err := repo.CreateAddress(context.Background(), &domain.Address{
+			CustomerID: customer.ID, Label: "home", City: "Tehran", Street: "Valiasr St", PostalCode: "1234567890",
+		})
+		assert.Empty(t, err)
+		assert.True(t, address.IsDefault)
+	})
+
+	t.Run("new default address replaces the default", func(t *testing.T) {
+		address, err := repo.CreateAddress(context.Background(), &domain.Address{
+			CustomerID: customer.ID, Label: "work", IsDefault: true, City: "Tehran", Street: "Enghelab St", PostalCode: "1234567891",
+		})
+		assert.Empty(t, err)
+		assert.True(t, address.IsDefault)
+
+		addresses, err := repo.ListAddresses(context.Background(), customer.ID)
+		assert.Empty(t, err)
+		assert.Len(t, addresses, 2)
+		assert.Equal(t, address.ID, addresses[0].ID)
+		assert.False(t, addresses[1].IsDefault)
+	})
+
+	t.Run("unsuccessful create with a taken label", func(t *testing.T) {
+		_, err := repo.CreateAddress(context.Background(), &domain.Address{
+			CustomerID: customer.ID, Label: "home", City: "Tehran", Street: "Azadi St", PostalCode: "1234567892",
+		})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusConflict, err.Code)
+	})
+}
+
+func TestPostgres_UpdateAddress(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	customer, other, _ := setUpOrderForeignObjects(t)
+	home, err := repo.CreateAddress(context.Background(), &domain.Address{
+		CustomerID: customer.ID, Label: "home", City: "Tehran", Street: "Valiasr St", PostalCode: "1234567890",
+	})
+	assert.Empty(t, err)
+	work, err := repo.CreateAddress(context.Background(), &domain.Address{
+		CustomerID: customer.ID, Label: "work", City: "Tehran", Street: "Enghelab St", PostalCode: "1234567891",
+	})
+	assert.Empty(t, err)
+
+	t.Run("successful update", func(t *testing.T) {
+		city := "Karaj"
+		isDefault := true
+		address, err := repo.UpdateAddress(context.Background(), customer.ID, &domain.AddressUpdateRequest{
+			AddressID: work.ID, City: &city, IsDefault: &isDefault,
+		})
+		assert.Empty(t, err)
+		assert.Equal(t, city, address.City)
+		assert.True(t, address.IsDefault)
+
+		previous, err := repo.GetAddress(context.Background(), home.ID, customer.ID)
+		assert.Empty(t, err)
+		assert.False(t, previous.IsDefault)
+	})
+
+	t.Run("unsuccessful update of an address of another customer", func(t *testing.T) {
+		city := "Karaj"
+		_, err := repo.UpdateAddress(context.Background(), other.ID, &domain.AddressUpdateRequest{AddressID: home.ID, City: &city})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_DeleteAddress(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	unit := "Unit 4"
+	pickup, err := repo.CreateAddress(context.Background(), &domain.Address{
+		CustomerID: sender.ID, Label: "warehouse", City: "Tehran", Street: "Valiasr St", Unit: &unit, PostalCode: "1234567890",
+	})
+	assert.Empty(t, err)
+	order, err := repo.CreateOrder(context.Background(), &domain.Order{
+		SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, PickupAddressID: &pickup.ID,
+	})
+	assert.Empty(t, err)
+	assert.Equal(t, "Valiasr St, Unit 4, Tehran", order.PickupAddress)
+	assert.Equal(t, receiver.Address, order.DropoffAddress)
+	assert.Empty(t, order.DropoffAddressID)
+
+	t.Run("unsuccessful delete of an address of another customer", func(t *testing.T) {
+		_, err := repo.DeleteAddress(context.Background(), pickup.ID, receiver.ID)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+
+	t.Run("orders keep their copy of a deleted address", func(t *testing.T) {
+		address, err := repo.DeleteAddress(context.Background(), pickup.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, pickup.ID, address.ID)
+
+		got, err := repo.GetOrder(context.Background(), order.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Empty(t, got.PickupAddressID)
+		assert.Equal(t, order.PickupAddress, got.PickupAddress)
+	})
+}
@@ -3,8 +3,10 @@ package tests
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPostgres_CreateCustomer(t *testing.T) {
@@ -59,3 +61,81 @@ func TestPostgres_GetCustomer(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, err.Code)
 	})
 }
+
+func TestPostgres_UpdateCustomer(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+	assert.Empty(t, err)
+
+	t.Run("successful update", func(t *testing.T) {
+		address := "elsewhere"
+		name := "name"
+		customer, err := repo.UpdateCustomer(context.Background(), sender.ID, &domain.CustomerUpdate{Address: &address, Name: &name})
+		assert.Empty(t, err)
+		assert.Equal(t, address, customer.Address)
+
+		audits, err := repo.GetCustomerAudits(context.Background(), sender.ID)
+		assert.Empty(t, err)
+		assert.Len(t, audits, 1)
+		assert.Equal(t, "address", audits[0].Field)
+		assert.Equal(t, "somewhere", *audits[0].OldValue)
+		assert.Equal(t, address, *audits[0].NewValue)
+	})
+
+	t.Run("orders keep the addresses they were created with", func(t *testing.T) {
+		got, err := repo.GetOrder(context.Background(), order.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, "somewhere", got.PickupAddress)
+		assert.Equal(t, "somewhere", got.DropoffAddress)
+		assert.Equal(t, sender.PhoneNumber, got.PickupContactPhone)
+	})
+
+	t.Run("unsuccessful update", func(t *testing.T) {
+		address := "elsewhere"
+		_, err := repo.UpdateCustomer(context.Background(), 1000, &domain.CustomerUpdate{Address: &address})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_PhoneVerification(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	name := "name"
+	phone := "09"
+	address := "somewhere"
+	postal := "some-code"
+	customer, err := repo.CreateCustomer(context.Background(), &name, &phone, &address, &postal)
+	assert.Empty(t, err)
+
+	t.Run("successful create", func(t *testing.T) {
+		_, err := repo.CreatePhoneVerification(context.Background(), customer.ID, "10", "hash", time.Minute)
+		assert.Empty(t, err)
+		verification, err := repo.CreatePhoneVerification(context.Background(), customer.ID, "11", "other", time.Minute)
+		assert.Empty(t, err)
+
+		got, err := repo.GetPhoneVerification(context.Background(), customer.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, verification.ID, got.ID)
+		assert.Equal(t, "11", got.PhoneNumber)
+		assert.False(t, got.Expired())
+	})
+
+	t.Run("successful attempt and delete", func(t *testing.T) {
+		verification, err := repo.GetPhoneVerification(context.Background(), customer.ID)
+		assert.Empty(t, err)
+		assert.Empty(t, repo.IncrementPhoneVerificationAttempts(context.Background(), verification.ID))
+
+		got, err := repo.GetPhoneVerification(context.Background(), customer.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, verification.Attempts+1, got.Attempts)
+
+		assert.Empty(t, repo.DeletePhoneVerification(context.Background(), verification.ID))
+		_, err = repo.GetPhoneVerification(context.Background(), customer.ID)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPostgres_StartIdempotentRequest(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	t.Run("successful start and replay", func(t *testing.T) {
+		record, created, err := repo.StartIdempotentRequest(context.Background(), 1, "key", "hash", time.Hour)
+		assert.Empty(t, err)
+		assert.True(t, created)
+		assert.False(t, record.Completed())
+
+		err = repo.CompleteIdempotentRequest(context.Background(), record.ID, 200, map[string]string{"Content-Type": "application/json"}, []byte(`{}`))
+		assert.Empty(t, err)
+
+		record, created, err = repo.StartIdempotentRequest(context.Background(), 1, "key", "hash", time.Hour)
+		assert.Empty(t, err)
+		assert.False(t, created)
+		assert.Equal(t, 200, record.ResponseCode)
+		assert.Equal(t, []byte(`{}`), record.ResponseBody)
+		assert.Equal(t, "application/json", record.ResponseHeaders["Content-Type"])
+	})
+
+	t.Run("separate users", func(t *testing.T) {
+		_, created, err := repo.StartIdempotentRequest(context.Background(), 2, "key", "hash", time.Hour)
+		assert.Empty(t, err)
+		assert.True(t, created)
+	})
+
+	t.Run("expired record is replaced", func(t *testing.T) {
+		_, created, err := repo.StartIdempotentRequest(context.Background(), 3, "key", "hash", -time.Minute)
+		assert.Empty(t, err)
+		assert.True(t, created)
+
+		_, created, err = repo.StartIdempotentRequest(context.Background(), 3, "key", "other", time.Hour)
+		assert.Empty(t, err)
+		assert.True(t, created)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"logistic-app/internal/app/domain"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func setUpOrderForeignObjects(t *testing.T) (sender *domain.Customer, receiver *domain.Customer, provider *domain.Provider) {
@@ -23,7 +24,7 @@ func setUpOrderForeignObjects(t *testing.T) (sender *domain.Customer, receiver *
 	if err != nil {
 		t.Error(err.Err)
 	}
-	provider, err = repo.CreateProvider(context.Background(), &test, &test)
+	provider, err = repo.CreateProvider(context.Background(), &test, &test, nil)
 	if err != nil {
 		t.Error(err.Err)
 	}
@@ -38,22 +39,38 @@ func TestPostgres_CreateOrder(t *testing.T) {
 
 	t.Run("successful create", func(t *testing.T) {
 		product := "phone"
-		order, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, &product)
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, Product: &product})
 		assert.Empty(t, err)
 		assert.Equal(t, product, *order.Product)
 		assert.Equal(t, sender.ID, order.SenderID)
 		assert.Equal(t, receiver.ID, order.ReceiverID)
 		assert.Equal(t, provider.ID, order.ProviderID)
+		assert.Equal(t, sender.PhoneNumber, order.PickupContactPhone)
+		assert.Equal(t, sender.Address, order.PickupAddress)
+		assert.Equal(t, receiver.PhoneNumber, order.DropoffContactPhone)
+		assert.Equal(t, *receiver.Name, *order.DropoffContactName)
 	})
 
 	t.Run("successful create with no name", func(t *testing.T) {
-		order, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 		assert.Empty(t, err)
 		assert.Empty(t, order.Product)
 		assert.Equal(t, sender.ID, order.SenderID)
 		assert.Equal(t, receiver.ID, order.ReceiverID)
 		assert.Equal(t, provider.ID, order.ProviderID)
 	})
+
+	t.Run("successful create with a parcel", func(t *testing.T) {
+		parcel := domain.Parcel{Weight: 1, Length: 50, Width: 40, Height: 30, DeclaredValue: 5000000, Fragile: true}
+		assert.Empty(t, parcel.Normalize("parcel"))
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, Parcel: parcel})
+		assert.Empty(t, err)
+
+		got, err := repo.GetOrder(context.Background(), order.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, parcel, got.Parcel)
+		assert.Equal(t, 12.0, got.Parcel.ChargeableWeight)
+	})
 }
 
 func TestPostgres_GetOrder(t *testing.T) {
@@ -64,7 +81,7 @@ func TestPostgres_GetOrder(t *testing.T) {
 
 	t.Run("successful get", func(t *testing.T) {
 		product := "phone"
-		act, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, &product)
+		act, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, Product: &product})
 		assert.Empty(t, err)
 
 		order, err := repo.GetOrder(context.Background(), act.ID, sender.ID)
@@ -84,6 +101,15 @@ func TestPostgres_GetOrder(t *testing.T) {
 		assert.NotEmpty(t, err)
 		assert.Equal(t, http.StatusNotFound, err.Code)
 	})
+
+	t.Run("unsuccessful get of an order of other customers", func(t *testing.T) {
+		act, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+
+		_, err = repo.GetOrderWithForeignObjects(context.Background(), act.ID, 1000)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
 }
 
 func TestPostgres_UpdateOrderStatus(t *testing.T) {
@@ -91,7 +117,7 @@ func TestPostgres_UpdateOrderStatus(t *testing.T) {
 	defer tearUpSuite()
 
 	sender, receiver, provider := setUpOrderForeignObjects(t)
-	order, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+	order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 	if err != nil {
 		t.Error(err.Err)
 	}
@@ -129,11 +155,11 @@ func TestPostgres_GetOngoingOrders(t *testing.T) {
 	sender, receiver, provider := setUpOrderForeignObjects(t)
 
 	t.Run("successful get", func(t *testing.T) {
-		order1, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+		order1, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 		assert.Empty(t, err)
 		_, err = repo.UpdateOrderStatus(context.Background(), order1.ID, domain.GetOrderStatus().Pending)
 		assert.Empty(t, err)
-		order2, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+		order2, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 		assert.Empty(t, err)
 
 		orders, err := repo.GetOngoingOrders(context.Background())
@@ -143,6 +169,43 @@ func TestPostgres_GetOngoingOrders(t *testing.T) {
 	})
 }
 
+func TestPostgres_GetUnbookedOrders(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+
+	t.Run("pending bookings of unpicked orders", func(t *testing.T) {
+		booked, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		err = repo.UpdateOrderBooking(context.Background(), booked.ID, domain.GetOrderBookingStatus().Booked, 1)
+		assert.Empty(t, err)
+		failed, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		err = repo.UpdateOrderBooking(context.Background(), failed.ID, domain.GetOrderBookingStatus().Failed, 5)
+		assert.Empty(t, err)
+		pickedUp, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		_, err = repo.UpdateOrderStatus(context.Background(), pickedUp.ID, domain.GetOrderStatus().PickedUp)
+		assert.Empty(t, err)
+		pending, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		err = repo.UpdateOrderBooking(context.Background(), pending.ID, domain.GetOrderBookingStatus().Pending, 2)
+		assert.Empty(t, err)
+
+		orders, err := repo.GetUnbookedOrders(context.Background(), time.Now().Add(time.Minute))
+		assert.Empty(t, err)
+		assert.Equal(t, 1, len(orders))
+		assert.Equal(t, pending.ID, orders[0].ID)
+		assert.Equal(t, uint(2), orders[0].BookingAttempts)
+		assert.Equal(t, 1, len(orders[0].Parcels))
+
+		orders, err = repo.GetUnbookedOrders(context.Background(), time.Now().Add(-time.Minute))
+		assert.Empty(t, err)
+		assert.Empty(t, orders)
+	})
+}
+
 func TestPostgres_UpdateOrderNotification(t *testing.T) {
 	tearUpSuite := setupSuite()
 	defer tearUpSuite()
@@ -150,7 +213,7 @@ func TestPostgres_UpdateOrderNotification(t *testing.T) {
 	sender, receiver, provider := setUpOrderForeignObjects(t)
 
 	t.Run("successful update notified_user", func(t *testing.T) {
-		order, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 		assert.Empty(t, err)
 		assert.Equal(t, false, order.NotifiedReceiver)
 
@@ -169,13 +232,13 @@ func TestPostgres_GetProvidersMeanDeliveryTime(t *testing.T) {
 
 	sender, receiver, provider := setUpOrderForeignObjects(t)
 	test2 := "test-2"
-	provider2, err := repo.CreateProvider(context.Background(), &test2, &test2)
+	provider2, err := repo.CreateProvider(context.Background(), &test2, &test2, nil)
 	if err != nil {
 		t.Error(err)
 	}
 
 	t.Run("successful get mean delivery time", func(t *testing.T) {
-		order1, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider.ID, nil)
+		order1, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
 		assert.Empty(t, err)
 		order1, err = repo.UpdateOrderStatus(context.Background(), order1.ID, domain.GetOrderStatus().PickedUp)
 		assert.Empty(t, err)
@@ -185,7 +248,7 @@ func TestPostgres_GetProvidersMeanDeliveryTime(t *testing.T) {
 		assert.Empty(t, err)
 		order1DelTime := float32(order1.DeliveryDate.Sub(*order1.PickedUpDate).Hours() / 24)
 
-		order2, err := repo.CreateOrder(context.Background(), sender.ID, receiver.ID, provider2.ID, nil)
+		order2, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider2.ID})
 		assert.Empty(t, err)
 		order2, err = repo.UpdateOrderStatus(context.Background(), order2.ID, domain.GetOrderStatus().PickedUp)
 		assert.Empty(t, err)
@@ -215,3 +278,273 @@ func TestPostgres_GetProvidersMeanDeliveryTime(t *testing.T) {
 		}
 	})
 }
+
+func TestPostgres_GetProvidersOnTimeRateAndLoad(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	for i := 0; i < 2; i++ {
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		if i == 0 {
+			_, err = repo.UpdateOrderStatus(context.Background(), order.ID, domain.GetOrderStatus().PickedUp)
+			assert.Empty(t, err)
+			_, err = repo.UpdateOrderStatus(context.Background(), order.ID, domain.GetOrderStatus().Delivered)
+			assert.Empty(t, err)
+		}
+	}
+
+	t.Run("on time rate of the delivered orders", func(t *testing.T) {
+		rates, err := repo.GetProvidersOnTimeRate(context.Background(), 3)
+		assert.Empty(t, err)
+		assert.Len(t, rates, 1)
+		assert.Equal(t, int64(1), rates[0].Delivered)
+		assert.Equal(t, float64(1), rates[0].OnTimeRate)
+	})
+
+	t.Run("load of the recent orders", func(t *testing.T) {
+		loads, err := repo.GetProvidersLoad(context.Background(), time.Now().Add(-time.Hour))
+		assert.Empty(t, err)
+		assert.Len(t, loads, 1)
+		assert.Equal(t, int64(2), loads[0].Orders)
+
+		loads, err = repo.GetProvidersLoad(context.Background(), time.Now().Add(time.Hour))
+		assert.Empty(t, err)
+		assert.Empty(t, loads)
+	})
+}
+
+func TestPostgres_PreviewOrder(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, _ := setUpOrderForeignObjects(t)
+
+	order, err := repo.PreviewOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID})
+	assert.Empty(t, err)
+	assert.Equal(t, sender.PostalCode, order.PickupPostalCode)
+	assert.Equal(t, receiver.PhoneNumber, order.DropoffContactPhone)
+	assert.Empty(t, order.ID)
+}
+
+func TestPostgres_UpdateParcelStatus(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	order := &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID}
+	order.SetParcels([]domain.Parcel{{Weight: 1, Pieces: 1}, {Weight: 2, Pieces: 1}})
+	order, err := repo.CreateOrder(context.Background(), order)
+	assert.Empty(t, err)
+
+	parcels, err := repo.GetOrderParcels(context.Background(), order.ID)
+	assert.Empty(t, err)
+	assert.Len(t, parcels, 2)
+	assert.Len(t, parcels[0].TrackingNumber, 12)
+	assert.NotEqual(t, parcels[0].TrackingNumber, parcels[1].TrackingNumber)
+	assert.Equal(t, 2.0, parcels[1].Weight)
+
+	t.Run("order is picked up with its first parcel", func(t *testing.T) {
+		got, err := repo.UpdateParcelStatus(context.Background(), parcels[0].ID, domain.GetOrderStatus().PickedUp)
+		assert.Empty(t, err)
+		assert.Equal(t, domain.GetOrderStatus().PickedUp, got.Status)
+		assert.NotEmpty(t, got.PickedUpDate)
+	})
+
+	t.Run("order is partially delivered and still ongoing", func(t *testing.T) {
+		got, err := repo.UpdateParcelStatus(context.Background(), parcels[0].ID, domain.GetOrderStatus().Delivered)
+		assert.Empty(t, err)
+		assert.Equal(t, domain.GetOrderStatus().PartiallyDelivered, got.Status)
+		assert.Empty(t, got.DeliveryDate)
+
+		orders, err := repo.GetOngoingOrders(context.Background())
+		assert.Empty(t, err)
+		assert.Len(t, orders, 1)
+	})
+
+	t.Run("order is delivered with its last parcel", func(t *testing.T) {
+		got, err := repo.UpdateParcelStatus(context.Background(), parcels[1].ID, domain.GetOrderStatus().Delivered)
+		assert.Empty(t, err)
+		assert.Equal(t, domain.GetOrderStatus().Delivered, got.Status)
+		assert.NotEmpty(t, got.DeliveryDate)
+
+		got, err = repo.GetOrderWithForeignObjects(context.Background(), order.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Len(t, got.Parcels, 2)
+		assert.Equal(t, domain.GetOrderStatus().Delivered, got.Parcels[1].Status)
+	})
+}
+
+func TestPostgres_GetTrackedOrder(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+	assert.Empty(t, err)
+	assert.Len(t, order.TrackingCode, 12)
+	_, err = repo.UpdateOrderStatus(context.Background(), order.ID, domain.GetOrderStatus().PickedUp)
+	assert.Empty(t, err)
+	_, err = repo.UpdateOrderStatus(context.Background(), order.ID, domain.GetOrderStatus().PickedUp)
+	assert.Empty(t, err)
+
+	t.Run("successful get with the history of the order", func(t *testing.T) {
+		got, err := repo.GetTrackedOrder(context.Background(), order.TrackingCode)
+		assert.Empty(t, err)
+		assert.Equal(t, order.ID, got.ID)
+		assert.Len(t, got.Parcels, 1)
+		assert.Len(t, got.History, 2)
+		assert.Equal(t, domain.GetOrderStatus().ProviderSeen, got.History[0].Status)
+		assert.Equal(t, domain.GetOrderStatus().PickedUp, got.History[1].Status)
+	})
+
+	t.Run("unsuccessful get of an unknown code", func(t *testing.T) {
+		_, err := repo.GetTrackedOrder(context.Background(), "000000000000")
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_ListIncomingOrders(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	var orders []*domain.Order
+	for range 3 {
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		orders = append(orders, order)
+	}
+	_, err := repo.UpdateOrderStatus(context.Background(), orders[0].ID, domain.GetOrderStatus().PickedUp)
+	assert.Empty(t, err)
+
+	t.Run("latest orders of the receiver first", func(t *testing.T) {
+		got, total, err := repo.ListIncomingOrders(context.Background(), receiver.ID, &domain.OrderListRequest{Pagination: domain.Pagination{Limit: 2}})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, got, 2)
+		assert.Equal(t, orders[2].ID, got[0].ID)
+		assert.Len(t, got[0].Parcels, 1)
+	})
+
+	t.Run("orders with the status", func(t *testing.T) {
+		status := domain.GetOrderStatus().PickedUp
+		got, total, err := repo.ListIncomingOrders(context.Background(), receiver.ID, &domain.OrderListRequest{Pagination: domain.Pagination{Limit: 10}, Status: &status})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, orders[0].ID, got[0].ID)
+	})
+
+	t.Run("no orders sent to the sender", func(t *testing.T) {
+		got, total, err := repo.ListIncomingOrders(context.Background(), sender.ID, &domain.OrderListRequest{Pagination: domain.Pagination{Limit: 10}})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.Empty(t, got)
+	})
+}
+
+func TestPostgres_UpdateOrderDelivery(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	order, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+	assert.Empty(t, err)
+
+	t.Run("successful update before the pickup", func(t *testing.T) {
+		instructions := "leave it with the concierge"
+		order.HoldAtLocation, order.DeliveryInstructions = true, &instructions
+		_, err := repo.UpdateOrderDelivery(context.Background(), order)
+		assert.Empty(t, err)
+
+		got, err := repo.GetOrder(context.Background(), order.ID, receiver.ID)
+		assert.Empty(t, err)
+		assert.True(t, got.HoldAtLocation)
+		assert.Equal(t, instructions, *got.DeliveryInstructions)
+	})
+
+	t.Run("successful revert of a rejected update", func(t *testing.T) {
+		previous := *order
+		previous.HoldAtLocation, previous.DeliveryInstructions = false, nil
+		err := repo.RevertOrderDelivery(context.Background(), &previous)
+		assert.Empty(t, err)
+
+		got, err := repo.GetOrder(context.Background(), order.ID, receiver.ID)
+		assert.Empty(t, err)
+		assert.False(t, got.HoldAtLocation)
+		assert.Nil(t, got.DeliveryInstructions)
+	})
+
+	t.Run("unsuccessful update after the pickup", func(t *testing.T) {
+		_, err := repo.UpdateOrderStatus(context.Background(), order.ID, domain.GetOrderStatus().PickedUp)
+		assert.Empty(t, err)
+
+		order.HoldAtLocation = true
+		_, err = repo.UpdateOrderDelivery(context.Background(), order)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusConflict, err.Code)
+
+		got, err := repo.GetOrder(context.Background(), order.ID, receiver.ID)
+		assert.Empty(t, err)
+		assert.False(t, got.HoldAtLocation)
+	})
+}
+
+func TestPostgres_GetDeliverySamples(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	delivered, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+	assert.Empty(t, err)
+	_, err = repo.UpdateOrderStatus(context.Background(), delivered.ID, domain.GetOrderStatus().PickedUp)
+	assert.Empty(t, err)
+	_, err = repo.UpdateOrderStatus(context.Background(), delivered.ID, domain.GetOrderStatus().Delivered)
+	assert.Empty(t, err)
+	ongoing, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+	assert.Empty(t, err)
+
+	t.Run("delivered orders of the provider", func(t *testing.T) {
+		samples, err := repo.GetDeliverySamples(context.Background(), provider.ID, time.Now().AddDate(0, 0, -1), 10)
+		assert.Empty(t, err)
+		assert.Len(t, samples, 1)
+		assert.Equal(t, receiver.PostalCode, samples[0].DropoffPostalCode)
+		assert.False(t, samples[0].DeliveryDate.IsZero())
+
+		samples, err = repo.GetDeliverySamples(context.Background(), provider.ID, time.Now().AddDate(0, 0, 1), 10)
+		assert.Empty(t, err)
+		assert.Empty(t, samples)
+	})
+
+	t.Run("latest delivered orders up to the limit", func(t *testing.T) {
+		latest, err := repo.CreateOrder(context.Background(), &domain.Order{SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID})
+		assert.Empty(t, err)
+		_, err = repo.UpdateOrderStatus(context.Background(), latest.ID, domain.GetOrderStatus().PickedUp)
+		assert.Empty(t, err)
+		_, err = repo.UpdateOrderStatus(context.Background(), latest.ID, domain.GetOrderStatus().Delivered)
+		assert.Empty(t, err)
+
+		samples, err := repo.GetDeliverySamples(context.Background(), provider.ID, time.Now().AddDate(0, 0, -1), 10)
+		assert.Empty(t, err)
+		assert.Len(t, samples, 2)
+
+		samples, err = repo.GetDeliverySamples(context.Background(), provider.ID, time.Now().AddDate(0, 0, -1), 1)
+		assert.Empty(t, err)
+		assert.Len(t, samples, 1)
+	})
+
+	t.Run("successful update of the estimate", func(t *testing.T) {
+		estimate := domain.EstimateDelivery(ongoing, []*domain.DeliverySample{{
+			DropoffPostalCode: receiver.PostalCode, CreatedAt: time.Now(), PickedUpDate: time.Now(), DeliveryDate: time.Now().AddDate(0, 0, 2),
+		}}, time.Now())
+		assert.Empty(t, repo.UpdateOrderEstimate(context.Background(), ongoing.ID, &estimate))
+
+		got, err := repo.GetOrder(context.Background(), ongoing.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.NotEmpty(t, got.EstimatedDelivery.Date)
+		assert.Equal(t, domain.GetEstimateBasis().Provider, *got.EstimatedDelivery.Basis)
+		assert.Equal(t, uint(1), got.EstimatedDelivery.Samples)
+	})
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestPostgres_CreateOrUpdatePeriodicTask(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	name := "test-task"
+
+	t.Run("successful run", func(t *testing.T) {
+		task, err := repo.CreateOrUpdatePeriodicTask(context.Background(), name, 10, false, nil)
+		assert.Empty(t, err)
+		assert.False(t, task.Failed)
+		assert.NotEmpty(t, task.LastRunTime)
+		assert.NotEmpty(t, task.LastSuccessTime)
+	})
+
+	t.Run("failed run keeps last success", func(t *testing.T) {
+		before, err := repo.GetPeriodicTask(context.Background(), name)
+		assert.Empty(t, err)
+
+		e := "failed"
+		_, err = repo.CreateOrUpdatePeriodicTask(context.Background(), name, 10, true, &e)
+		assert.Empty(t, err)
+
+		task, err := repo.GetPeriodicTask(context.Background(), name)
+		assert.Empty(t, err)
+		assert.True(t, task.Failed)
+		assert.Equal(t, before.LastSuccessTime.Unix(), task.LastSuccessTime.Unix())
+	})
+
+	t.Run("successful run clears failure", func(t *testing.T) {
+		_, err := repo.CreateOrUpdatePeriodicTask(context.Background(), name, 10, false, nil)
+		assert.Empty(t, err)
+
+		task, err := repo.GetPeriodicTask(context.Background(), name)
+		assert.Empty(t, err)
+		assert.False(t, task.Failed)
+	})
+}
+
+func TestPostgres_GetPeriodicTask(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	t.Run("unsuccessful get", func(t *testing.T) {
+		_, err := repo.GetPeriodicTask(context.Background(), "unknown")
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_GetMigrationVersion(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	t.Run("successful get", func(t *testing.T) {
+		version, err := repo.GetMigrationVersion(context.Background())
+		assert.Empty(t, err)
+		assert.Equal(t, version.Expected, version.Applied)
+	})
+}
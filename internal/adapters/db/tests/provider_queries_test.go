@@ -5,12 +5,17 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log"
 	"logistic-app/internal/adapters/db"
+	"logistic-app/internal/app/domain"
 	"logistic-app/internal/app/ports"
 	"net/http"
 	"testing"
 )
 
-var repo ports.Repo
+var repo interface {
+	ports.Repo
+	ports.RateLimitStore
+	ports.IdempotencyStore
+}
 var e error
 
 func setupSuite() func() {
@@ -31,22 +36,25 @@ func TestPostgres_CreateProvider(t *testing.T) {
 		name := "test"
 		url := "test"
 
-		actProv, err := repo.CreateProvider(context.Background(), &name, &url)
+		actProv, err := repo.CreateProvider(context.Background(), &name, &url, nil)
 		assert.Empty(t, err)
 		assert.Equal(t, name, actProv.Name)
 		assert.Equal(t, url, actProv.Url)
+		assert.True(t, actProv.Active)
+		assert.Equal(t, domain.GetProviderVerificationStatus().Pending, actProv.VerificationStatus)
 	})
 
 	t.Run("duplicate create", func(t *testing.T) {
 		name := "test-2"
 		url := "test-2"
 
-		_, err := repo.CreateProvider(context.Background(), &name, &url)
+		_, err := repo.CreateProvider(context.Background(), &name, &url, nil)
 		assert.Empty(t, err)
 
-		_, err = repo.CreateProvider(context.Background(), &name, &url)
+		_, err = repo.CreateProvider(context.Background(), &name, &url, nil)
 		assert.NotEmpty(t, err)
-		assert.Equal(t, http.StatusInternalServerError, err.Code)
+		assert.Equal(t, http.StatusConflict, err.Code)
+		assert.Equal(t, "already exists", err.ApiErr.Errors["name"])
 	})
 }
 
@@ -56,7 +64,7 @@ func TestPostgres_GetProvider(t *testing.T) {
 
 	t.Run("successful get", func(t *testing.T) {
 		test := "test"
-		provider, err := repo.CreateProvider(context.Background(), &test, &test)
+		provider, err := repo.CreateProvider(context.Background(), &test, &test, nil)
 		assert.Empty(t, err)
 
 		actProv, err := repo.GetProvider(context.Background(), provider.ID)
@@ -79,9 +87,9 @@ func TestPostgres_GetAllProviders(t *testing.T) {
 	t.Run("successful get", func(t *testing.T) {
 		test1 := "test-1"
 		test2 := "test-2"
-		_, err := repo.CreateProvider(context.Background(), &test1, &test1)
+		_, err := repo.CreateProvider(context.Background(), &test1, &test1, nil)
 		assert.Empty(t, err)
-		_, err = repo.CreateProvider(context.Background(), &test2, &test2)
+		_, err = repo.CreateProvider(context.Background(), &test2, &test2, nil)
 		assert.Empty(t, err)
 
 		providers, err := repo.GetAllProviders(context.Background())
@@ -92,3 +100,142 @@ func TestPostgres_GetAllProviders(t *testing.T) {
 		}
 	})
 }
+
+func TestPostgres_ListProviders(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	names := []string{"test-1", "test-2", "test-3"}
+	for i := range names {
+		_, err := repo.CreateProvider(context.Background(), &names[i], &names[i], nil)
+		assert.Empty(t, err)
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		providers, total, err := repo.ListProviders(context.Background(), &domain.ProviderListRequest{Limit: 2})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, 2, len(providers))
+		assert.Equal(t, "test-1", providers[0].Name)
+		assert.Equal(t, "test-2", providers[1].Name)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		providers, total, err := repo.ListProviders(context.Background(), &domain.ProviderListRequest{Limit: 2, Offset: 2})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, 1, len(providers))
+		assert.Equal(t, "test-3", providers[0].Name)
+	})
+
+	t.Run("all without a limit", func(t *testing.T) {
+		providers, total, err := repo.ListProviders(context.Background(), &domain.ProviderListRequest{})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, 3, len(providers))
+	})
+}
+
+func TestPostgres_UpdateProvider(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	name := "test"
+	url := "test"
+	provider, err := repo.CreateProvider(context.Background(), &name, &url, nil)
+	assert.Empty(t, err)
+
+	t.Run("successful deactivate", func(t *testing.T) {
+		active := false
+		updated, err := repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{ProviderID: provider.ID, Active: &active})
+		assert.Empty(t, err)
+		assert.False(t, updated.Active)
+		assert.Equal(t, name, updated.Name)
+
+		got, err := repo.GetProvider(context.Background(), provider.ID)
+		assert.Empty(t, err)
+		assert.False(t, got.Active)
+	})
+
+	t.Run("successful update", func(t *testing.T) {
+		_, err := repo.UpdateProviderVerification(context.Background(), provider.ID, provider.Url, domain.GetProviderVerificationStatus().Verified, nil)
+		assert.Empty(t, err)
+
+		newURL := "https://example.com/status"
+		updated, err := repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{ProviderID: provider.ID, Url: &newURL})
+		assert.Empty(t, err)
+		assert.Equal(t, newURL, updated.Url)
+		assert.False(t, updated.Active)
+		assert.Equal(t, domain.GetProviderVerificationStatus().Pending, updated.VerificationStatus)
+	})
+
+	t.Run("successful quote url clear", func(t *testing.T) {
+		quoteURL := "https://example.com/quote"
+		updated, err := repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{
+			ProviderID: provider.ID,
+			QuoteUrl:   domain.Nullable[string]{Value: &quoteURL, Set: true},
+		})
+		assert.Empty(t, err)
+		assert.Equal(t, &quoteURL, updated.QuoteUrl)
+
+		updated, err = repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{
+			ProviderID: provider.ID,
+			QuoteUrl:   domain.Nullable[string]{Set: true},
+		})
+		assert.Empty(t, err)
+		assert.Nil(t, updated.QuoteUrl)
+	})
+
+	t.Run("unsuccessful update", func(t *testing.T) {
+		_, err := repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{ProviderID: 1000, Url: &url})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_UpdateProviderVerification(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	name := "test"
+	url := "test"
+	provider, err := repo.CreateProvider(context.Background(), &name, &url, nil)
+	assert.Empty(t, err)
+
+	t.Run("failed verification keeps the provider pending", func(t *testing.T) {
+		detail := "provider test responded with 502"
+		updated, err := repo.UpdateProviderVerification(context.Background(), provider.ID, url, domain.GetProviderVerificationStatus().Pending, &detail)
+		assert.Empty(t, err)
+		assert.Equal(t, detail, *updated.VerificationDetail)
+		assert.NotEmpty(t, updated.VerificationCheckedAt)
+		assert.False(t, updated.TakesOrders())
+	})
+
+	t.Run("successful verification", func(t *testing.T) {
+		updated, err := repo.UpdateProviderVerification(context.Background(), provider.ID, provider.Url, domain.GetProviderVerificationStatus().Verified, nil)
+		assert.Empty(t, err)
+		assert.Empty(t, updated.VerificationDetail)
+		assert.True(t, updated.TakesOrders())
+		assert.Equal(t, name, updated.Name)
+	})
+
+	t.Run("verification of a previous url is discarded", func(t *testing.T) {
+		newURL := "https://example.com/status"
+		_, err := repo.UpdateProvider(context.Background(), &domain.ProviderUpdateRequest{ProviderID: provider.ID, Url: &newURL})
+		assert.Empty(t, err)
+
+		_, err = repo.UpdateProviderVerification(context.Background(), provider.ID, url, domain.GetProviderVerificationStatus().Verified, nil)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+
+		got, err := repo.GetProvider(context.Background(), provider.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, domain.GetProviderVerificationStatus().Pending, got.VerificationStatus)
+	})
+
+	t.Run("unsuccessful verification", func(t *testing.T) {
+		_, err := repo.UpdateProviderVerification(context.Background(), 1000, url, domain.GetProviderVerificationStatus().Verified, nil)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPostgres_ReplaceTariffs(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	_, _, provider := setUpOrderForeignObjects(t)
+
+	t.Run("successful replace", func(t *testing.T) {
+		_, err := repo.ReplaceTariffs(context.Background(), provider.ID, []*domain.Tariff{
+			{MaxWeight: 1, Price: 50000, DeliveryDays: 2},
+		})
+		assert.Empty(t, err)
+		_, err = repo.ReplaceTariffs(context.Background(), provider.ID, []*domain.Tariff{
+			{MaxWeight: 5, Price: 90000, DeliveryDays: 3},
+			{MaxWeight: 2, Price: 70000, DeliveryDays: 3},
+		})
+		assert.Empty(t, err)
+
+		tariffs, err := repo.ListTariffs(context.Background(), provider.ID)
+		assert.Empty(t, err)
+		assert.Len(t, tariffs, 2)
+		assert.Equal(t, float64(2), tariffs[0].MaxWeight)
+	})
+
+	t.Run("unsuccessful replace with the same weight twice", func(t *testing.T) {
+		_, err := repo.ReplaceTariffs(context.Background(), provider.ID, []*domain.Tariff{
+			{MaxWeight: 1, Price: 50000, DeliveryDays: 2},
+			{MaxWeight: 1, Price: 60000, DeliveryDays: 1},
+		})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusConflict, err.Code)
+
+		tariffs, _ := repo.ListTariffs(context.Background(), provider.ID)
+		assert.Len(t, tariffs, 2)
+	})
+}
+
+func TestPostgres_Quote(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	_, err := repo.UpdateProviderVerification(context.Background(), provider.ID, provider.Url, domain.GetProviderVerificationStatus().Verified, nil)
+	assert.Empty(t, err)
+
+	quote, err := repo.CreateQuote(context.Background(), &domain.Quote{
+		CustomerID:        sender.ID,
+		PickupPostalCode:  sender.PostalCode,
+		DropoffPostalCode: receiver.PostalCode,
+		Parcel:            domain.Parcel{Weight: 2},
+		Options: []*domain.QuoteOption{
+			{Rank: 1, ProviderID: provider.ID, ProviderName: provider.Name, Price: 70000, DeliveryDays: 3, Source: "tariff"},
+		},
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	assert.Empty(t, err)
+
+	t.Run("quotable providers take orders", func(t *testing.T) {
+		providers, err := repo.GetQuotableProviders(context.Background(), sender.PostalCode, receiver.PostalCode)
+		assert.Empty(t, err)
+		assert.Len(t, providers, 1)
+	})
+
+	t.Run("quote of another customer", func(t *testing.T) {
+		got, err := repo.GetQuote(context.Background(), quote.ID, sender.ID)
+		assert.Empty(t, err)
+		assert.Equal(t, int64(70000), got.Options[0].Price)
+
+		_, err = repo.GetQuote(context.Background(), quote.ID, receiver.ID)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+
+	t.Run("order keeps the price of its quote", func(t *testing.T) {
+		price := int64(70000)
+		order, err := repo.CreateOrder(context.Background(), &domain.Order{
+			SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, QuoteID: &quote.ID, Price: &price,
+		})
+		assert.Empty(t, err)
+		assert.Equal(t, price, *order.Price)
+	})
+
+	t.Run("unsuccessful order to other postal codes than the quote", func(t *testing.T) {
+		other, err := repo.CreateQuote(context.Background(), &domain.Quote{
+			CustomerID: sender.ID, PickupPostalCode: "1111111111", DropoffPostalCode: receiver.PostalCode,
+			Parcel: domain.Parcel{Weight: 2}, ExpiresAt: time.Now().Add(time.Minute),
+		})
+		assert.Empty(t, err)
+
+		_, err = repo.CreateOrder(context.Background(), &domain.Order{
+			SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID, QuoteID: &other.ID,
+		})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+	})
+}
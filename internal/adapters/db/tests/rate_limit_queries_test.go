@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestPostgres_TakeToken(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	limit := &domain.RateLimit{Requests: 2, Period: time.Hour}
+
+	t.Run("successful take until empty", func(t *testing.T) {
+		result, err := repo.TakeToken(context.Background(), "test", limit)
+		assert.Empty(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+
+		result, err = repo.TakeToken(context.Background(), "test", limit)
+		assert.Empty(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = repo.TakeToken(context.Background(), "test", limit)
+		assert.Empty(t, err)
+		assert.False(t, result.Allowed)
+		assert.NotEmpty(t, result.RetryAfter)
+	})
+
+	t.Run("separate keys", func(t *testing.T) {
+		result, err := repo.TakeToken(context.Background(), "test-2", limit)
+		assert.Empty(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
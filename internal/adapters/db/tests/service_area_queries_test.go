@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"net/http"
+	"testing"
+)
+
+func TestPostgres_ImportServiceAreas(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	_, _, provider := setUpOrderForeignObjects(t)
+
+	t.Run("import adds to the service areas", func(t *testing.T) {
+		_, err := repo.ImportServiceAreas(context.Background(), provider.ID, []*domain.ServiceArea{
+			{PostalCodeFrom: "11", PostalCodeTo: "14"},
+		}, false)
+		assert.Empty(t, err)
+		areas, err := repo.ImportServiceAreas(context.Background(), provider.ID, []*domain.ServiceArea{
+			{PostalCodeFrom: "31", PostalCodeTo: "31"},
+		}, false)
+		assert.Empty(t, err)
+		assert.Len(t, areas, 2)
+	})
+
+	t.Run("import replaces the service areas", func(t *testing.T) {
+		areas, err := repo.ImportServiceAreas(context.Background(), provider.ID, []*domain.ServiceArea{
+			{PostalCodeFrom: "6", PostalCodeTo: "7"},
+		}, true)
+		assert.Empty(t, err)
+		assert.Len(t, areas, 1)
+		assert.Equal(t, "6", areas[0].PostalCodeFrom)
+	})
+
+	t.Run("unsuccessful import for a missing provider", func(t *testing.T) {
+		_, err := repo.ImportServiceAreas(context.Background(), provider.ID+100, []*domain.ServiceArea{
+			{PostalCodeFrom: "6", PostalCodeTo: "7"},
+		}, false)
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	})
+}
+
+func TestPostgres_ServiceAreaCoverage(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	sender, receiver, provider := setUpOrderForeignObjects(t)
+	name := "everywhere"
+	everywhere, err := repo.CreateProvider(context.Background(), &name, &name, nil)
+	assert.Empty(t, err)
+	_, err = repo.ImportServiceAreas(context.Background(), provider.ID, []*domain.ServiceArea{
+		{PostalCodeFrom: sender.PostalCode[:2], PostalCodeTo: sender.PostalCode[:2]},
+	}, false)
+	assert.Empty(t, err)
+
+	t.Run("list keeps the providers serving the postal codes", func(t *testing.T) {
+		from := sender.PostalCode
+		providers, total, err := repo.ListProviders(context.Background(), &domain.ProviderListRequest{
+			From: &from,
+		})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, providers, 2)
+
+		to := "9999999999"
+		providers, total, err = repo.ListProviders(context.Background(), &domain.ProviderListRequest{
+			From: &from, To: &to,
+		})
+		assert.Empty(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, everywhere.ID, providers[0].ID)
+	})
+
+	t.Run("unsuccessful order outside the service areas", func(t *testing.T) {
+		receiver.PostalCode = "9999999999"
+		_, err := repo.UpdateCustomer(context.Background(), receiver.ID, &domain.CustomerUpdate{PostalCode: &receiver.PostalCode})
+		assert.Empty(t, err)
+
+		_, err = repo.CreateOrder(context.Background(), &domain.Order{
+			SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: provider.ID,
+		})
+		assert.NotEmpty(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+		assert.Contains(t, err.ApiErr.Errors, "dropoff_postal_code")
+		assert.NotContains(t, err.ApiErr.Errors, "pickup_postal_code")
+
+		_, err = repo.CreateOrder(context.Background(), &domain.Order{
+			SenderID: sender.ID, ReceiverID: receiver.ID, ProviderID: everywhere.ID,
+		})
+		assert.Empty(t, err)
+	})
+}
+
+func TestPostgres_CoverageQuery(t *testing.T) {
+	tearUpSuite := setupSuite()
+	defer tearUpSuite()
+
+	_, _, ranged := setUpOrderForeignObjects(t)
+	name := "everywhere"
+	everywhere, err := repo.CreateProvider(context.Background(), &name, &name, nil)
+	assert.Empty(t, err)
+	_, err = repo.ImportServiceAreas(context.Background(), ranged.ID, []*domain.ServiceArea{
+		{PostalCodeFrom: "3100", PostalCodeTo: "3199"},
+		{PostalCodeFrom: "11", PostalCodeTo: "11"},
+	}, false)
+	assert.Empty(t, err)
+
+	for code, providers := range map[string][]uint{
+		"3100000000": {ranged.ID, everywhere.ID}, // lower bound
+		"3199999999": {ranged.ID, everywhere.ID}, // upper bound
+		"3099999999": {everywhere.ID},
+		"3200000000": {everywhere.ID},
+		"1134567890": {ranged.ID, everywhere.ID}, // prefix
+		"1200000000": {everywhere.ID},
+		"315":        {everywhere.ID}, // shorter than the bounds
+	} {
+		t.Run(code, func(t *testing.T) {
+			found, _, err := repo.ListProviders(context.Background(), &domain.ProviderListRequest{From: &code})
+			assert.Empty(t, err)
+			ids := make([]uint, len(found))
+			for i, provider := range found {
+				ids[i] = provider.ID
+			}
+			assert.ElementsMatch(t, providers, ids)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"logistic-app/internal/common/telemetry"
+)
+
+const parentContextKey = "tracing:parent_context"
+
+// tracingPlugin creates a span for every query run through gorm.
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", beforeQuery("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", afterQuery),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", beforeQuery("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", afterQuery),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", beforeQuery("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", afterQuery),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeQuery("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterQuery),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", beforeQuery("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", afterQuery),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeQuery("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterQuery),
+	}
+	return errors.Join(registrations...)
+}
+
+func beforeQuery(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, _ := telemetry.Tracer().Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		tx.InstanceSet(parentContextKey, tx.Statement.Context)
+		tx.Statement.Context = ctx
+	}
+}
+
+func afterQuery(tx *gorm.DB) {
+	span := trace.SpanFromContext(tx.Statement.Context)
+	// chained calls reuse the statement, so the next query must not become a child of this one
+	if parent, ok := tx.InstanceGet(parentContextKey); ok {
+		tx.Statement.Context = parent.(context.Context)
+	}
+	span.SetAttributes(
+		attribute.String("db.collection.name", tx.Statement.Table),
+		attribute.String("db.query.text", tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	var err error
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		err = tx.Error
+	}
+	telemetry.End(span, err)
+}
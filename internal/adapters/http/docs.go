@@ -0,0 +1,168 @@
+package http
+
+import (
+	"fmt"
+	"logistic-app/internal/adapters/http/middlewares"
+	"logistic-app/internal/adapters/http/models"
+	"logistic-app/internal/adapters/http/openapi"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs/"
+
+	apiTitle   = "Logistic App API"
+	apiVersion = "1.0.0"
+
+	bearerAuth = "bearerAuth"
+	adminToken = "adminToken"
+)
+
+var pathWildcard = regexp.MustCompile(`{[^}]+}`)
+
+// newDocument describes the routes of every version in an OpenAPI document, their parameters
+// and bodies are read from the tags of the request and response types.
+func newDocument(versions []version) *openapi.Document {
+	doc := openapi.New(apiTitle, apiVersion)
+	doc.Info.Description = "Responses are wrapped in an envelope holding the data, or the error as an RFC 7807 problem " +
+		"with a stable code listed in the README, and the meta of the response."
+	doc.Components.SecuritySchemes[bearerAuth] = &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	doc.Components.SecuritySchemes[adminToken] = &openapi.SecurityScheme{Type: "apiKey", In: "header", Name: middlewares.AdminTokenHeader}
+	problem := doc.Components.SchemaOf(reflect.TypeOf(models.Envelope{}))
+
+	for _, v := range versions {
+		for _, r := range v.routes {
+			op := describeRoute(doc, problem, r)
+			if v.name != "" {
+				op.OperationID = v.name + "-" + r.name
+				op.Tags = []string{v.name + " " + r.tag}
+			}
+			doc.AddOperation(r.method, v.prefix()+r.path, op)
+
+			if v.name == legacyVersion && configs.LegacyRoutes == "alias" {
+				legacy := *op
+				legacy.OperationID = "legacy-" + r.name
+				legacy.Tags = []string{"legacy"}
+				legacy.Deprecated = true
+				legacy.Description = strings.TrimSpace(fmt.Sprintf("Deprecated alias of %s%s. %s", v.prefix(), r.path, op.Description))
+				doc.AddOperation(r.method, "/api"+r.path, &legacy)
+			}
+		}
+	}
+	return doc
+}
+
+func describeRoute(doc *openapi.Document, problem *openapi.Schema, r route) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: r.name,
+		Summary:     r.summary,
+		Tags:        []string{r.tag},
+	}
+
+	success := http.StatusOK
+	if r.created {
+		success = http.StatusCreated
+	}
+	resp := op.AddResponse(success, "application/json", envelope(doc, r.handle.response))
+	resp.Headers = make(map[string]*openapi.Header)
+	if r.location != "" {
+		resp.Headers["Location"] = &openapi.Header{Description: "Path of the created object.", Schema: &openapi.Schema{Type: "string"}}
+	}
+	if r.cache != "" {
+		resp.Headers["Cache-Control"] = &openapi.Header{Schema: &openapi.Schema{Type: "string", Enum: []any{r.cache}}}
+		if r.cache != "no-store" {
+			resp.Headers["ETag"] = &openapi.Header{
+				Description: "Validator of the data, send it in If-None-Match to get 304 when it is not modified.",
+				Schema:      &openapi.Schema{Type: "string"},
+			}
+			op.AddResponse(http.StatusNotModified, "", nil)
+		}
+	}
+	for status, response := range r.responses {
+		op.AddResponse(status, "application/json", envelope(doc, reflect.TypeOf(response)))
+	}
+
+	if r.handle.request != nil {
+		t := r.handle.request
+		op.Parameters = doc.Components.ParametersOf(t)
+		if r.method == http.MethodPost || r.method == http.MethodPut || r.method == http.MethodPatch {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{"application/json": {Schema: doc.Components.SchemaOf(t)}},
+			}
+			if mediaType := domain.RawBodyMediaType(t); mediaType != "" {
+				op.RequestBody.Content = map[string]*openapi.MediaType{mediaType: {Schema: &openapi.Schema{Type: "string"}}}
+			}
+			op.AddResponse(http.StatusRequestEntityTooLarge, "application/json", problem)
+			op.AddResponse(http.StatusUnsupportedMediaType, "application/json", problem)
+		}
+		op.AddResponse(http.StatusBadRequest, "application/json", problem)
+	}
+	if pathWildcard.MatchString(r.path) {
+		op.AddResponse(http.StatusNotFound, "application/json", problem)
+	}
+	if r.auth {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		op.AddResponse(http.StatusUnauthorized, "application/json", problem)
+	}
+	if r.admin {
+		op.Security = []map[string][]string{{adminToken: {}}}
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        middlewares.AdminTokenHeader,
+			In:          "header",
+			Description: "The ADMIN_TOKEN of the server.",
+			Required:    true,
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+		op.AddResponse(http.StatusUnauthorized, "application/json", problem)
+	}
+	if r.idempotent {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        middlewares.IdempotencyKeyHeader,
+			In:          "header",
+			Description: "Replays the response of an earlier request sent with the same key.",
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+		op.AddResponse(http.StatusConflict, "application/json", problem)
+		op.AddResponse(http.StatusUnprocessableEntity, "application/json", problem)
+	}
+	if r.limit != nil {
+		resp := op.AddResponse(http.StatusTooManyRequests, "application/json", problem)
+		resp.Headers = map[string]*openapi.Header{
+			"Retry-After": {Description: "Seconds to wait before retrying.", Schema: &openapi.Schema{Type: "integer"}},
+		}
+		op.Description = fmt.Sprintf("Limited to %d requests per %d seconds.", r.limit.Requests, int(r.limit.Period.Seconds()))
+	}
+	op.AddResponse(http.StatusInternalServerError, "application/json", problem)
+
+	return op
+}
+
+var pagedType = reflect.TypeOf((*domain.Paged)(nil)).Elem()
+
+// envelope returns the schema of the envelope of successful responses holding the result,
+// results of list requests hold their items.
+func envelope(doc *openapi.Document, t reflect.Type) *openapi.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	data := doc.Components.SchemaOf(t)
+	if reflect.PointerTo(t).Implements(pagedType) {
+		items, _ := t.FieldByName("Items")
+		data = doc.Components.SchemaOf(items.Type)
+	}
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data": data,
+			"meta": doc.Components.SchemaOf(reflect.TypeOf(models.Meta{})),
+		},
+		Required: []string{"data", "meta"},
+	}
+}
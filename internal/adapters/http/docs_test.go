@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoutesAreDescribed(t *testing.T) {
+	versions := NewServer(stubService{}, nil, nil).versions()
+	document := newDocument(versions)
+
+	for _, v := range versions {
+		names := make(map[string]bool)
+		for _, r := range v.routes {
+			path := v.prefix() + r.path
+			t.Run(r.method+" "+path, func(t *testing.T) {
+				assert.NotEmpty(t, r.name, "route has no name")
+				assert.False(t, names[r.name], "route name %q is not unique in its version", r.name)
+				names[r.name] = true
+				assert.NotEmpty(t, r.summary, "route has no summary")
+				assert.NotEmpty(t, r.tag, "route has no tag")
+				assert.NotNil(t, r.handle.serve, "route has no handler")
+
+				op := document.Operation(r.method, path)
+				require.NotNil(t, op, "route is missing from the document")
+				if r.created {
+					assert.Contains(t, op.Responses, "201")
+				} else {
+					assert.Contains(t, op.Responses, "200")
+				}
+
+				for _, wildcard := range pathWildcard.FindAllString(r.path, -1) {
+					name := strings.Trim(wildcard, "{}")
+					found := false
+					for _, param := range op.Parameters {
+						found = found || (param.In == "path" && param.Name == name)
+					}
+					assert.True(t, found, "path value %q is not bound by the request type", name)
+				}
+			})
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	handler := NewServer(stubService{}, nil, nil).Handler()
+
+	t.Run("Document", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", openAPIPath, http.NoBody))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var document struct {
+			OpenAPI    string                               `json:"openapi"`
+			Paths      map[string]map[string]map[string]any `json:"paths"`
+			Components struct {
+				Schemas map[string]map[string]any `json:"schemas"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+		assert.Equal(t, "3.0.3", document.OpenAPI)
+		assert.Contains(t, document.Paths["/api/v1/order/{order_id}/"], "get")
+		assert.Contains(t, document.Paths["/api/v2/order/"]["post"], "security")
+		assert.Contains(t, document.Paths["/api/v1/provider/{provider_id}/"]["patch"], "security")
+		assert.Equal(t, true, document.Paths["/api/order/"]["post"]["deprecated"])
+		assert.Contains(t, document.Components.Schemas, "Timeline")
+
+		customer := document.Components.Schemas["CustomerCreateRequest"]
+		assert.ElementsMatch(t, []any{"phone_number", "address", "postal_code"}, customer["required"])
+	})
+
+	t.Run("Docs Page", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", docsPath, http.NoBody))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, recorder.Body.String(), `data-spec="/api/openapi.json"`)
+	})
+}
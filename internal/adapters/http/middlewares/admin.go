@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"logistic-app/internal/adapters/http/models"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuth only lets through the requests holding the ADMIN_TOKEN in the X-Admin-Token header,
+// every request is rejected when no ADMIN_TOKEN is set.
+func AdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAdmin(r) {
+			models.WriteJSON(w, models.ReturnErrorResp(r.Context(), errors.Unauthorized()))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isAdmin(r *http.Request) bool {
+	token := r.Header.Get(AdminTokenHeader)
+	return configs.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(configs.AdminToken)) == 1
+}
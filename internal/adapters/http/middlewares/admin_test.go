@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/common/configs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuth(t *testing.T) {
+	handler := AdminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(token string) int {
+		request := httptest.NewRequest("PATCH", "http://localhost:8080/test/", http.NoBody)
+		if token != "" {
+			request.Header.Set(AdminTokenHeader, token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	defer func(token string) { configs.AdminToken = token }(configs.AdminToken)
+	configs.AdminToken = "admin-secret"
+
+	t.Run("Valid Token", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("admin-secret"))
+	})
+
+	t.Run("Missing Or Wrong Token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(""))
+		assert.Equal(t, http.StatusUnauthorized, serve("admin-secreT"))
+	})
+
+	t.Run("No Token Configured", func(t *testing.T) {
+		configs.AdminToken = ""
+		assert.Equal(t, http.StatusUnauthorized, serve(""))
+	})
+}
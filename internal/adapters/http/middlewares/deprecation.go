@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Deprecated marks the responses of routes under the from prefix as deprecated, linking to
+// their successor under the to prefix. The sunset, an HTTP date, is sent when it is set.
+func Deprecated(from, to, sunset string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, to+strings.TrimPrefix(r.URL.Path, from)))
+			if sunset != "" {
+				w.Header().Set("Sunset", sunset)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Redirect permanently redirects requests under the from prefix to the same path and
+// query under the to prefix, keeping their method and body.
+func Redirect(from, to string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := to + strings.TrimPrefix(r.URL.Path, from)
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stdErrors "errors"
+	"io"
+	"log/slog"
+	"logistic-app/internal/adapters/http/models"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// replayedHeaders are the response headers stored along with the body of an idempotent request.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// captureWriter writes the response to the client while keeping a copy of it.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	c.status = code
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Idempotency stores the response of authenticated requests sent with an Idempotency-Key header,
+// and replays it when the same user retries the same request with the same key.
+func Idempotency(store ports.IdempotencyStore) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			userID, ok := r.Context().Value(configs.UserIDKey).(uint)
+			if key == "" || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > 255 {
+				models.WriteJSON(w, models.ReturnErrorResp(r.Context(), errors.BadRequest(IdempotencyKeyHeader+" is too long")))
+				return
+			}
+
+			body, e := io.ReadAll(http.MaxBytesReader(w, r.Body, configs.MaxBodySize))
+			if e != nil {
+				err := errors.InternalServerError(e)
+				if maxBytesErr := (*http.MaxBytesError)(nil); stdErrors.As(e, &maxBytesErr) {
+					err = errors.RequestEntityTooLarge(maxBytesErr.Limit)
+				}
+				models.WriteJSON(w, models.ReturnErrorResp(r.Context(), err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			requestHash := hashRequest(r, body)
+
+			record, created, err := store.StartIdempotentRequest(r.Context(), userID, key, requestHash, configs.IdempotencyKeyTTL)
+			if err != nil {
+				models.WriteJSON(w, models.ReturnErrorResp(r.Context(), err))
+				return
+			}
+			if !created {
+				switch {
+				case record.RequestHash != requestHash:
+					err = errors.UnprocessableEntity(errors.CodeIdempotencyKeyReused, IdempotencyKeyHeader+" was already used with a different request")
+				case !record.Completed():
+					err = errors.Conflict(errors.CodeIdempotencyInFlight, "a request with this "+IdempotencyKeyHeader+" is still in progress")
+				}
+				if err != nil {
+					models.WriteJSON(w, models.ReturnErrorResp(r.Context(), err))
+					return
+				}
+				for name, value := range record.ResponseHeaders {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.ResponseCode)
+				_, _ = w.Write(record.ResponseBody)
+				return
+			}
+
+			capture := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+			served := false
+			defer func() {
+				// a panicking handler leaves no response to store, the key is released so the request can be retried
+				if served {
+					return
+				}
+				if err := store.DeleteIdempotentRequest(context.WithoutCancel(r.Context()), record.ID); err != nil {
+					slog.ErrorContext(r.Context(), "could not release idempotency key", "error", err.Err)
+				}
+			}()
+			next.ServeHTTP(capture, r)
+			served = true
+
+			// server errors are not stored, so that the client can retry them
+			if capture.status >= http.StatusInternalServerError {
+				err = store.DeleteIdempotentRequest(r.Context(), record.ID)
+			} else {
+				headers := make(map[string]string)
+				for _, name := range replayedHeaders {
+					if value := w.Header().Get(name); value != "" {
+						headers[name] = value
+					}
+				}
+				err = store.CompleteIdempotentRequest(r.Context(), record.ID, capture.status, headers, capture.body.Bytes())
+			}
+			if err != nil {
+				slog.ErrorContext(r.Context(), "could not store idempotent response", "error", err.Err)
+			}
+		})
+	}
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeIdempotencyStore struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyStore) StartIdempotentRequest(ctx context.Context, userID uint, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, bool, *errors.AppError) {
+	if record, ok := f.records[key]; ok {
+		return record, false, nil
+	}
+	record := &domain.IdempotencyRecord{ID: uint(len(f.records) + 1), UserID: userID, IdempotencyKey: key, RequestHash: requestHash}
+	f.records[key] = record
+	return record, true, nil
+}
+
+func (f *fakeIdempotencyStore) CompleteIdempotentRequest(ctx context.Context, recordID uint, code int, headers map[string]string, body []byte) *errors.AppError {
+	for _, record := range f.records {
+		if record.ID == recordID {
+			record.ResponseCode = code
+			record.ResponseHeaders = headers
+			record.ResponseBody = body
+		}
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyStore) DeleteIdempotentRequest(ctx context.Context, recordID uint) *errors.AppError {
+	for key, record := range f.records {
+		if record.ID == recordID {
+			delete(f.records, key)
+		}
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	handler := Idempotency(&fakeIdempotencyStore{records: map[string]*domain.IdempotencyRecord{}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		}))
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest("POST", "http://localhost:8080/api/order/", strings.NewReader(body))
+		request.Header.Set(IdempotencyKeyHeader, "key-1")
+		return request.WithContext(context.WithValue(request.Context(), configs.UserIDKey, uint(6)))
+	}
+
+	t.Run("First Request", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest(`{"receiver_id": 2}`))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Replayed Request", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest(`{"receiver_id": 2}`))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"id": 1}`, recorder.Body.String())
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "true", recorder.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Reused Key With Different Body", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest(`{"receiver_id": 3}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Key Released When The Handler Panics", func(t *testing.T) {
+		store := &fakeIdempotencyStore{records: map[string]*domain.IdempotencyRecord{}}
+		panicking := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("handler failed")
+		}))
+		assert.Panics(t, func() { panicking.ServeHTTP(httptest.NewRecorder(), newRequest(`{"receiver_id": 2}`)) })
+		assert.Empty(t, store.records)
+	})
+}
@@ -1,7 +1,7 @@
 package middlewares
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"regexp"
 	"time"
@@ -29,10 +29,27 @@ func MiddlewareStack(xs ...Middleware) Middleware {
 	}
 }
 
+// statusRecorder keeps the status code written by the handler for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		n := time.Now()
-		next.ServeHTTP(w, r)
-		log.Println(r.Method, r.URL.Path, time.Since(n))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.InfoContext(r.Context(), "request handled",
+			"method", r.Method,
+			"path", cleanURLPath(r.URL.Path),
+			"status", rec.status,
+			"duration", time.Since(n),
+		)
 	})
 }
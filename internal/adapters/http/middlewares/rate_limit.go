@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/adapters/http/models"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimiter returns a constructor of per-route rate limiting middlewares sharing the given store.
+// Requests are limited per authenticated user, or per client ip for anonymous requests.
+func RateLimiter(store ports.RateLimitStore) func(route string, requests int, period time.Duration) Middleware {
+	return func(route string, requests int, period time.Duration) Middleware {
+		limit := &domain.RateLimit{Requests: requests, Period: period}
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				key := route + "|" + clientKey(r)
+				result, err := store.TakeToken(r.Context(), key, limit)
+				if err != nil {
+					// an unavailable store must not take the api down with it
+					slog.WarnContext(r.Context(), "rate limit store failed", "error", err.Err)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds())))
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+				w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+				if !result.Allowed {
+					w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+					models.WriteJSON(w, models.ReturnErrorResp(r.Context(), errors.TooManyRequests()))
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+}
+
+func clientKey(r *http.Request) string {
+	if userID, ok := r.Context().Value(configs.UserIDKey).(uint); ok {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip:" + clientIP(r)
+}
+
+func clientIP(r *http.Request) string {
+	if configs.RateLimitTrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
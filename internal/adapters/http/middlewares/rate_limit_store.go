@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"context"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryRateLimitStore is an in-process token bucket store, limits are not shared between replicas.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	maxPeriod time.Duration
+	evictedAt time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryRateLimitStore) TakeToken(ctx context.Context, key string, limit *domain.RateLimit) (*domain.RateLimitResult, *errors.AppError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updatedAt: now}
+		m.buckets[key] = b
+	}
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+elapsed*limit.RefillRate())
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	m.maxPeriod = max(m.maxPeriod, limit.Period)
+	m.evict(now)
+	return limit.Result(allowed, b.tokens), nil
+}
+
+// evict drops buckets which have been refilled completely, as they are the same as missing ones.
+func (m *MemoryRateLimitStore) evict(now time.Time) {
+	if now.Sub(m.evictedAt) < time.Minute {
+		return
+	}
+	m.evictedAt = now
+	for key, b := range m.buckets {
+		if now.Sub(b.updatedAt) > m.maxPeriod {
+			delete(m.buckets, key)
+		}
+	}
+}
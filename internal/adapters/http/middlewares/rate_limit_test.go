@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore_TakeToken(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := &domain.RateLimit{Requests: 2, Period: time.Minute}
+
+	t.Run("Allows Burst", func(t *testing.T) {
+		result, err := store.TakeToken(context.Background(), "burst", limit)
+		assert.Empty(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+
+		result, _ = store.TakeToken(context.Background(), "burst", limit)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		result, _ = store.TakeToken(context.Background(), "burst", limit)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 30*time.Second, result.RetryAfter)
+	})
+
+	t.Run("Refills Over Time", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		result, _ := store.TakeToken(context.Background(), "burst", limit)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("Separate Keys", func(t *testing.T) {
+		result, _ := store.TakeToken(context.Background(), "other", limit)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func TestRateLimiter(t *testing.T) {
+	limit := RateLimiter(NewMemoryRateLimitStore())
+	handler := limit("test", 1, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	t.Run("Limits Client Ip", func(t *testing.T) {
+		request := httptest.NewRequest("POST", "http://localhost:8080/test/", http.NoBody)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "1", recorder.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("Limits Users Separately", func(t *testing.T) {
+		request := httptest.NewRequest("POST", "http://localhost:8080/test/", http.NoBody)
+		request = request.WithContext(context.WithValue(request.Context(), configs.UserIDKey, uint(6)))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/common/configs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var ctxID string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID, _ = r.Context().Value(configs.RequestIDKey).(string)
+	}))
+
+	t.Run("Keeps Given Request Id", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/", http.NoBody)
+		request.Header.Set(RequestIDHeader, "abc-123")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, "abc-123", ctxID)
+		assert.Equal(t, "abc-123", recorder.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("Generates Request Id", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/", http.NoBody)
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+		assert.NotEmpty(t, ctxID)
+		assert.Equal(t, ctxID, recorder.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("Replaces Invalid Request Id", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/", http.NoBody)
+		request.Header.Set(RequestIDHeader, "bad id\n")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+		assert.NotEqual(t, "bad id\n", ctxID)
+		assert.Equal(t, ctxID, recorder.Header().Get(RequestIDHeader))
+	})
+}
@@ -2,39 +2,134 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"logistic-app/internal/app/domain"
 	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// Response is the result of a handler, written to the client in an Envelope.
 type Response struct {
-	Result any `json:"result"`
-	Code   int `json:"code"`
+	Result  any
+	Code    int
+	Headers http.Header
+	Meta    *Meta
+}
+
+// Envelope is the body of every response, holding either the data or the error of the response.
+type Envelope struct {
+	Data  any             `json:"data"`
+	Error *errors.Problem `json:"error,omitempty"`
+	Meta  *Meta           `json:"meta"`
+}
+
+type Meta struct {
+	RequestID  string           `json:"request_id,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Pagination *domain.PageInfo `json:"pagination,omitempty"`
 }
 
 type ErrorFunc func() *errors.AppError
 
+func newMeta(ctx context.Context) *Meta {
+	requestID, _ := ctx.Value(configs.RequestIDKey).(string)
+	return &Meta{RequestID: requestID, Timestamp: time.Now().UTC()}
+}
+
+// ReturnResp returns the result with 200, results of list requests are returned
+// as their items along with the pagination in the meta.
 func ReturnResp(ctx context.Context, v any) *Response {
-	return &Response{
-		Result: v,
-		Code:   http.StatusOK,
+	resp := &Response{
+		Result:  v,
+		Code:    http.StatusOK,
+		Headers: make(http.Header),
+		Meta:    newMeta(ctx),
 	}
+	if page, ok := v.(domain.Paged); ok {
+		resp.Result = page.Data()
+		resp.Meta.Pagination = page.PageInfo()
+	}
+	return resp
 }
 
+// ReturnErrorResp logs the underlying error and returns the problem of the error,
+// identified by the id of the request.
 func ReturnErrorResp(ctx context.Context, err *errors.AppError) *Response {
 	if configs.LogError {
-		log.Println(err.Err)
+		level := slog.LevelWarn
+		if err.Code >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "request failed", "error", err.Err, "status", err.Code, "code", err.ApiErr.Code)
+	}
+	meta := newMeta(ctx)
+	problem := *err.ApiErr
+	if meta.RequestID != "" {
+		problem.Instance = "urn:request-id:" + meta.RequestID
 	}
-	return &Response{Result: err.ApiErr, Code: err.Code}
+	return &Response{Result: &problem, Code: err.Code, Headers: make(http.Header), Meta: meta}
 }
 
+// ETag returns a strong validator of the result and its pagination, the rest of the meta
+// is left out as it changes on every request.
+func (r *Response) ETag() (string, error) {
+	var pagination *domain.PageInfo
+	if r.Meta != nil {
+		pagination = r.Meta.Pagination
+	}
+	body, e := json.Marshal([]any{r.Result, pagination})
+	if e != nil {
+		return "", e
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified reports whether the If-None-Match header of the request matches the ETag header of the response.
+func (r *Response) NotModified(request *http.Request) bool {
+	etag := r.Headers.Get("ETag")
+	if etag == "" {
+		return false
+	}
+	for _, match := range strings.Split(request.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the response in an envelope as application/json.
 func WriteJSON(w http.ResponseWriter, resp *Response) {
+	for key, values := range resp.Headers {
+		w.Header()[key] = values
+	}
+	// errors are sent as application/json too, the problem is nested in the envelope rather than being the body
 	w.Header().Set("Content-Type", "application/json")
+	problem, isProblem := resp.Result.(*errors.Problem)
 	w.WriteHeader(resp.Code)
-	err := json.NewEncoder(w).Encode(resp.Result)
+	if resp.Code == http.StatusNotModified {
+		return
+	}
+
+	envelope := &Envelope{Meta: resp.Meta}
+	if envelope.Meta == nil {
+		envelope.Meta = &Meta{Timestamp: time.Now().UTC()}
+	}
+	if isProblem {
+		envelope.Error = problem
+	} else {
+		envelope.Data = resp.Result
+	}
+	err := json.NewEncoder(w).Encode(envelope)
 	if err != nil {
-		_ = json.NewEncoder(w).Encode(errors.InternalServerError(err).ApiErr)
+		_ = json.NewEncoder(w).Encode(&Envelope{Error: errors.InternalServerError(err).ApiErr, Meta: envelope.Meta})
 	}
 }
@@ -0,0 +1,200 @@
+package v2
+
+import (
+	"logistic-app/internal/app/domain"
+	"time"
+)
+
+// Order groups the parties and the dates of an order, unlike the flat order of v1.
+// Its parcel is the total of its parcels, null for orders created before parcels were taken.
+type Order struct {
+	ID               uint                  `json:"id"`
+	TrackingCode     string                `json:"tracking_code"`
+	Status           string                `json:"status"`
+	ProductName      *string               `json:"product_name"`
+	Parcel           *domain.Parcel        `json:"parcel"`
+	Parcels          []*domain.OrderParcel `json:"parcels,omitempty"`
+	Provider         *Party                `json:"provider"`
+	Sender           *Party                `json:"sender"`
+	Receiver         *Party                `json:"receiver"`
+	Pickup           *Location             `json:"pickup"`
+	Dropoff          *Location             `json:"dropoff"`
+	Quote            *Quote                `json:"quote"`
+	Delivery         *Delivery             `json:"delivery"`
+	Timeline         *Timeline             `json:"timeline"`
+	ReceiverNotified bool                  `json:"receiver_notified"`
+}
+
+// Party is a provider or a customer taking part in an order, its name is only known when it was loaded.
+type Party struct {
+	ID   uint    `json:"id"`
+	Name *string `json:"name,omitempty"`
+}
+
+// Location is the contact and the address a parcel is picked up from or dropped off at, as they were when the order was created.
+type Location struct {
+	ContactName  *string  `json:"contact_name"`
+	ContactPhone string   `json:"contact_phone"`
+	AddressID    *uint    `json:"address_id"`
+	Address      string   `json:"address"`
+	PostalCode   string   `json:"postal_code"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+// Quote is the quote the order was created from and its price, it is null for orders created without a quote.
+type Quote struct {
+	ID    *uint  `json:"id"`
+	Price *int64 `json:"price"`
+}
+
+// Delivery is the delivery asked by the receiver, its slot is null until the receiver reschedules the order.
+type Delivery struct {
+	Slot           *domain.DeliverySlot `json:"slot"`
+	HoldAtLocation bool                 `json:"hold_at_location"`
+	Instructions   *string              `json:"instructions"`
+}
+
+// Timeline holds the dates of the order, its estimated delivery is null when there is no estimate.
+type Timeline struct {
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	PickedUpAt        *time.Time               `json:"picked_up_at"`
+	DeliveredAt       *time.Time               `json:"delivered_at"`
+	EstimatedDelivery *domain.DeliveryEstimate `json:"estimated_delivery"`
+}
+
+type OrderCreateRequest struct {
+	ProviderID  uint    `json:"provider_id"`
+	Strategy    *string `json:"strategy" validate:"oneof=cheapest fastest on_time round_robin"`
+	ReceiverID  uint    `json:"receiver_id" validate:"required"`
+	ProductName *string `json:"product_name" validate:"max=255"`
+	// parcel of the shipment, or its parcels when it has more than one, the parcel of the quote is used when both are left out
+	Parcel  *domain.Parcel   `json:"parcel"`
+	Parcels []*domain.Parcel `json:"parcels" validate:"max=20"`
+	// saved addresses of the sender and the receiver, their default addresses are used when left out
+	PickupAddressID  *uint `json:"pickup_address_id"`
+	DropoffAddressID *uint `json:"dropoff_address_id"`
+	QuoteID          *uint `json:"quote_id"`
+}
+
+type OrderGetRequest struct {
+	OrderID uint `path:"order_id" json:"-"`
+}
+
+type OrderListRequest struct {
+	domain.Pagination
+	Status *string `query:"status" validate:"oneof=PENDING PROVIDER_SEEN PICKED_UP IN_PROGRESS PARTIALLY_DELIVERED DELIVERED"`
+}
+
+type OrderDeliveryUpdateRequest struct {
+	OrderID        uint                 `path:"order_id" json:"-"`
+	Slot           *domain.DeliverySlot `json:"slot"`
+	HoldAtLocation *bool                `json:"hold_at_location"`
+	Instructions   *string              `json:"instructions" validate:"max=500"`
+}
+
+func NewOrder(order *domain.Order) *Order {
+	resp := &Order{
+		ID:           order.ID,
+		TrackingCode: order.TrackingCode,
+		Status:       order.Status,
+		ProductName:  order.Product,
+		Parcels:      order.Parcels,
+		Provider:     &Party{ID: order.ProviderID},
+		Sender:       &Party{ID: order.SenderID},
+		Receiver:     &Party{ID: order.ReceiverID},
+		Pickup: &Location{
+			ContactName:  order.PickupContactName,
+			ContactPhone: order.PickupContactPhone,
+			AddressID:    order.PickupAddressID,
+			Address:      order.PickupAddress,
+			PostalCode:   order.PickupPostalCode,
+			Latitude:     order.PickupLatitude,
+			Longitude:    order.PickupLongitude,
+		},
+		Dropoff: &Location{
+			ContactName:  order.DropoffContactName,
+			ContactPhone: order.DropoffContactPhone,
+			AddressID:    order.DropoffAddressID,
+			Address:      order.DropoffAddress,
+			PostalCode:   order.DropoffPostalCode,
+			Latitude:     order.DropoffLatitude,
+			Longitude:    order.DropoffLongitude,
+		},
+		Delivery: &Delivery{
+			HoldAtLocation: order.HoldAtLocation,
+			Instructions:   order.DeliveryInstructions,
+		},
+		Timeline: &Timeline{
+			CreatedAt:   order.CreatedAt,
+			UpdatedAt:   order.UpdatedAt,
+			PickedUpAt:  order.PickedUpDate,
+			DeliveredAt: order.DeliveryDate,
+		},
+		ReceiverNotified: order.NotifiedReceiver,
+	}
+	if order.Parcel.Weight > 0 {
+		resp.Parcel = &order.Parcel
+	}
+	if order.EstimatedDelivery.Date != nil {
+		resp.Timeline.EstimatedDelivery = &order.EstimatedDelivery
+	}
+	if order.DeliverySlotStart != nil && order.DeliverySlotEnd != nil {
+		resp.Delivery.Slot = &domain.DeliverySlot{Start: *order.DeliverySlotStart, End: *order.DeliverySlotEnd}
+	}
+	if order.Price != nil {
+		resp.Quote = &Quote{ID: order.QuoteID, Price: order.Price}
+	}
+	if order.Provider != nil {
+		resp.Provider.Name = &order.Provider.Name
+	}
+	if order.Sender != nil {
+		resp.Sender.Name = order.Sender.Name
+	}
+	if order.Receiver != nil {
+		resp.Receiver.Name = order.Receiver.Name
+	}
+	return resp
+}
+
+// NewOrderPage converts the orders of the page, keeping its page info.
+func NewOrderPage(page *domain.Page[*domain.Order]) *domain.Page[*Order] {
+	orders := make([]*Order, len(page.Items))
+	for i, order := range page.Items {
+		orders[i] = NewOrder(order)
+	}
+	return &domain.Page[*Order]{Items: orders, Info: page.Info}
+}
+
+func (r *OrderCreateRequest) ToDomain() *domain.OrderCreateRequest {
+	return &domain.OrderCreateRequest{
+		ProviderID: r.ProviderID,
+		Strategy:   r.Strategy,
+		ReceiverID: r.ReceiverID,
+		Product:    r.ProductName,
+		Parcel:     r.Parcel,
+		Parcels:    r.Parcels,
+
+		PickupAddressID:  r.PickupAddressID,
+		DropoffAddressID: r.DropoffAddressID,
+		QuoteID:          r.QuoteID,
+	}
+}
+
+func (r *OrderGetRequest) ToDomain() *domain.OrderGetRequest {
+	return &domain.OrderGetRequest{OrderID: r.OrderID}
+}
+
+func (r *OrderListRequest) ToDomain() *domain.OrderListRequest {
+	return &domain.OrderListRequest{Pagination: r.Pagination, Status: r.Status}
+}
+
+func (r *OrderDeliveryUpdateRequest) ToDomain() *domain.OrderDeliveryUpdateRequest {
+	return &domain.OrderDeliveryUpdateRequest{
+		OrderID:        r.OrderID,
+		Slot:           r.Slot,
+		HoldAtLocation: r.HoldAtLocation,
+		Instructions:   r.Instructions,
+	}
+}
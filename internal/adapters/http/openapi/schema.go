@@ -0,0 +1,274 @@
+package openapi
+
+import (
+	"encoding/json"
+	"logistic-app/internal/app/domain"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// SchemaOf returns the schema of the type. Named structs are added to the components
+// and referenced, their properties are read from the json tags and constrained by the
+// validate, pattern and default tags the binder of the domain package uses.
+// Fields bound from the path or the query string are left out.
+func (c *Components) SchemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v, ok := valueType(t); ok {
+		schema := c.SchemaOf(v)
+		schema.Nullable = true
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: c.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + c.register(t)}
+	}
+	return &Schema{}
+}
+
+// valueType returns the type of the value of a domain.Nullable, which is described as its value.
+func valueType(t reflect.Type) (reflect.Type, bool) {
+	n, ok := reflect.Zero(t).Interface().(interface{ ValueType() reflect.Type })
+	if !ok {
+		return nil, false
+	}
+	return n.ValueType(), true
+}
+
+// register adds the schema of the named struct to the components once and returns its name.
+func (c *Components) register(t reflect.Type) string {
+	if name, ok := c.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := c.Schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	c.names[t] = name
+	// reserve the name before the fields are described, so recursive types reference it
+	c.Schemas[name] = &Schema{}
+	*c.Schemas[name] = *c.structSchema(t)
+	return name
+}
+
+func (c *Components) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	c.addFields(schema, t)
+	return schema
+}
+
+func (c *Components) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			c.addFields(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("path") != "" || field.Tag.Get("query") != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = c.FieldSchema(field)
+		if isRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// FieldSchema returns the schema of the field, constrained by its tags.
+func (c *Components) FieldSchema(field reflect.StructField) *Schema {
+	schema := c.SchemaOf(field.Type)
+	if schema.Ref != "" {
+		return schema
+	}
+
+	t := field.Type
+	if v, ok := valueType(t); ok {
+		t = v
+	}
+	if t.Kind() == reflect.Pointer {
+		schema.Nullable = true
+		t = t.Elem()
+	}
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			applyBound(schema, t, name, arg)
+		case "notblank":
+			if t.Kind() == reflect.String && schema.MinLength == nil {
+				schema.MinLength = integer(1)
+			}
+		case "url":
+			schema.Format = "uri"
+		case "e164":
+			schema.Pattern = domain.E164Pattern
+		case "oneof":
+			for _, option := range strings.Fields(arg) {
+				schema.Enum = append(schema.Enum, parseValue(t, option))
+			}
+		}
+	}
+	if pattern := field.Tag.Get("pattern"); pattern != "" {
+		schema.Pattern = pattern
+	}
+	if def, ok := field.Tag.Lookup("default"); ok {
+		schema.Default = parseValue(t, def)
+	}
+	return schema
+}
+
+// ParametersOf describes the fields of the struct bound from the path and the query string.
+func (c *Components) ParametersOf(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, c.ParametersOf(field.Type)...)
+			continue
+		}
+		if name := field.Tag.Get("path"); name != "" {
+			params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: c.FieldSchema(field)})
+		} else if name := field.Tag.Get("query"); name != "" {
+			params = append(params, &Parameter{Name: name, In: "query", Required: isRequired(field), Schema: c.FieldSchema(field)})
+		}
+	}
+	return params
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func applyBound(schema *Schema, t reflect.Type, rule, arg string) {
+	bound, e := strconv.ParseFloat(arg, 64)
+	if e != nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if rule == "min" {
+			schema.MinLength = integer(bound)
+		} else {
+			schema.MaxLength = integer(bound)
+		}
+	case reflect.Slice, reflect.Array:
+		if rule == "min" {
+			schema.MinItems = integer(bound)
+		} else {
+			schema.MaxItems = integer(bound)
+		}
+	default:
+		if rule == "min" {
+			schema.Minimum = float(bound)
+		} else {
+			schema.Maximum = float(bound)
+		}
+	}
+}
+
+// parseValue converts a value written in a tag to the json type of the field.
+func parseValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, e := strconv.ParseBool(value); e == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, e := strconv.ParseInt(value, 10, 64); e == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, e := strconv.ParseFloat(value, 64); e == nil {
+			return n
+		}
+	}
+	return value
+}
+
+func float(n float64) *float64 {
+	return &n
+}
+
+func integer(n float64) *int {
+	i := int(n)
+	return &i
+}
@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City  string  `json:"city" validate:"required,oneof=Tehran Shiraz"`
+	Notes *string `json:"notes,omitempty" validate:"max=100"`
+}
+
+type testRequest struct {
+	domain.Pagination
+	ID        uint           `path:"id"`
+	Phone     string         `json:"phone" validate:"required,e164"`
+	Website   string         `json:"website" validate:"url"`
+	Code      string         `json:"code" pattern:"^[0-9]{5}$"`
+	Count     int            `json:"count" validate:"min=1,max=10"`
+	Addresses []*testAddress `json:"addresses" validate:"min=1"`
+	Labels    map[string]any `json:"labels"`
+	At        time.Time      `json:"at"`
+	Internal  string         `json:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	doc := New("test", "1")
+	schema := doc.Components.SchemaOf(reflect.TypeOf(testRequest{}))
+	assert.Equal(t, "#/components/schemas/testRequest", schema.Ref)
+
+	request := doc.Components.Schemas["testRequest"]
+	assert.Equal(t, []string{"phone"}, request.Required)
+	assert.NotContains(t, request.Properties, "id")
+	assert.NotContains(t, request.Properties, "limit")
+	assert.NotContains(t, request.Properties, "Internal")
+
+	assert.Equal(t, domain.E164Pattern, request.Properties["phone"].Pattern)
+	assert.Equal(t, "uri", request.Properties["website"].Format)
+	assert.Equal(t, "^[0-9]{5}$", request.Properties["code"].Pattern)
+	assert.Equal(t, 1.0, *request.Properties["count"].Minimum)
+	assert.Equal(t, 10.0, *request.Properties["count"].Maximum)
+	assert.Equal(t, 1, *request.Properties["addresses"].MinItems)
+	assert.Equal(t, "#/components/schemas/testAddress", request.Properties["addresses"].Items.Ref)
+	assert.Equal(t, "object", request.Properties["labels"].Type)
+	assert.Equal(t, "date-time", request.Properties["at"].Format)
+
+	address := doc.Components.Schemas["testAddress"]
+	assert.Equal(t, []any{"Tehran", "Shiraz"}, address.Properties["city"].Enum)
+	assert.True(t, address.Properties["notes"].Nullable)
+	assert.Equal(t, 100, *address.Properties["notes"].MaxLength)
+}
+
+func TestParametersOf(t *testing.T) {
+	doc := New("test", "1")
+	params := doc.Components.ParametersOf(reflect.TypeOf(testRequest{}))
+	assert.Equal(t, 3, len(params))
+
+	byName := make(map[string]*Parameter)
+	for _, p := range params {
+		byName[p.Name] = p
+	}
+	assert.Equal(t, "path", byName["id"].In)
+	assert.True(t, byName["id"].Required)
+	assert.Equal(t, "query", byName["limit"].In)
+	assert.Equal(t, int64(10), byName["limit"].Schema.Default)
+	assert.Equal(t, 100.0, *byName["limit"].Schema.Maximum)
+	assert.Equal(t, int64(0), byName["offset"].Schema.Default)
+}
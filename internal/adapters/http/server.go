@@ -2,74 +2,422 @@ package http
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"logistic-app/internal/adapters/http/middlewares"
 	"logistic-app/internal/adapters/http/models"
+	v2 "logistic-app/internal/adapters/http/models/v2"
+	"logistic-app/internal/adapters/http/openapi"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/app/ports"
 	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
 	"net/http"
+	"os"
 	"reflect"
+	"time"
 )
 
 type responseFunc func(request *http.Request) *models.Response
 
 type Server struct {
-	listenAddr string
-	service    ports.Service
+	listenAddr       string
+	service          ports.Service
+	rateLimitStore   ports.RateLimitStore
+	idempotencyStore ports.IdempotencyStore
 }
 
-func NewServer(service ports.Service) *Server {
+func NewServer(service ports.Service, rateLimitStore ports.RateLimitStore, idempotencyStore ports.IdempotencyStore) *Server {
 	return &Server{
-		listenAddr: configs.ServerURL,
-		service:    service,
+		listenAddr:       configs.ServerURL,
+		service:          service,
+		rateLimitStore:   rateLimitStore,
+		idempotencyStore: idempotencyStore,
 	}
 }
 
-func (s *Server) Run() {
-	router := http.NewServeMux()
+// route describes an endpoint, both for the router and for the OpenAPI document.
+type route struct {
+	method  string
+	path    string
+	name    string // operation id of the route in its version, also keys its rate limit
+	summary string
+	tag     string
+
+	auth       bool
+	admin      bool // requires the ADMIN_TOKEN in the X-Admin-Token header
+	idempotent bool
+	limit      *domain.RateLimit
+	created    bool   // responds with 201 instead of 200
+	location   string // path of the created object in the version, its wildcard is replaced by the ID of the result
+	cache      string // Cache-Control header of the responses, cached responses have an ETag as well
+
+	responses map[int]any // other non problem responses of the route
+	handle    handler
+}
+
+// handler serves a route, its request and result types are taken from the function it calls
+// so the document always describes what the route binds and returns.
+type handler struct {
+	request  reflect.Type // type bound by performWith, nil for routes without input
+	response reflect.Type // result type of a successful response
+	serve    responseFunc
+}
+
+// legacyVersion is the version served under the unversioned /api/ paths, based on LEGACY_ROUTES.
+const legacyVersion = "v1"
+
+// version groups the routes mounted under /api/<name>/, routes of the unnamed version are not versioned.
+type version struct {
+	name   string
+	routes []route
+}
+
+func (v version) prefix() string {
+	if v.name == "" {
+		return "/api"
+	}
+	return "/api/" + v.name
+}
+
+func (s *Server) versions() []version {
+	return []version{
+		{name: "", routes: append(s.healthRoutes(), s.trackingRoutes()...)},
+		{name: "v1", routes: s.routesV1()},
+		{name: "v2", routes: s.routesV2()},
+	}
+}
+
+func (s *Server) healthRoutes() []route {
+	healthResponses := map[int]any{http.StatusServiceUnavailable: domain.HealthReport{}}
+
+	return []route{
+		{method: "GET", path: "/health/", name: "health", tag: "health",
+			summary:   "Readiness probe, alias of /api/health/ready/",
+			responses: healthResponses, cache: "no-store",
+			handle: withHealthStatus(perform(s.service.Readiness))},
+		{method: "GET", path: "/health/live/", name: "liveness", tag: "health",
+			summary:   "Liveness probe",
+			responses: healthResponses, cache: "no-store",
+			handle: withHealthStatus(perform(s.service.Liveness))},
+		{method: "GET", path: "/health/ready/", name: "readiness", tag: "health",
+			summary:   "Readiness probe checking every dependency",
+			responses: healthResponses, cache: "no-store",
+			handle: withHealthStatus(perform(s.service.Readiness))},
+	}
+}
+
+// trackingRoutes serve anyone holding a tracking code without authentication, they are rate limited so the codes
+// cannot be enumerated.
+func (s *Server) trackingRoutes() []route {
+	return []route{
+		{method: "GET", path: "/track/{code}/", name: "track-order", tag: "tracking",
+			summary: "Track an order by its tracking code, without its parties, addresses and contacts",
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			cache:   "public, no-cache",
+			handle:  performWith(s.service.GetTracking)},
+	}
+}
+
+func (s *Server) routesV1() []route {
+	return []route{
+		{method: "GET", path: "/providers/", name: "list-providers", tag: "providers",
+			summary: "List providers ordered by id",
+			cache:   "public, max-age=60",
+			handle:  performWith(s.service.GetProviders)},
+		{method: "GET", path: "/providers/report/", name: "providers-report", tag: "providers",
+			summary: "Mean delivery time of the providers in days",
+			cache:   "public, max-age=300",
+			handle:  perform(s.service.GetProvidersMeanDelTime)},
+		{method: "POST", path: "/provider/", name: "create-provider", tag: "providers",
+			summary: "Register a provider",
+			admin:   true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			created: true, location: "/provider/{provider_id}/",
+			handle: performWith(s.service.CreateProvider)},
+		{method: "GET", path: "/provider/{provider_id}/", name: "get-provider", tag: "providers",
+			summary: "Get a provider",
+			cache:   "public, max-age=60",
+			handle:  performWith(s.service.GetProvider)},
+		{method: "PATCH", path: "/provider/{provider_id}/", name: "update-provider", tag: "providers",
+			summary: "Update a provider, an inactive provider takes no new orders",
+			admin:   true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performWith(s.service.UpdateProvider)},
+		{method: "POST", path: "/provider/{provider_id}/verify/", name: "verify-provider", tag: "providers",
+			summary: "Probe the url of a provider again in the background, it takes orders once it responds as expected",
+			admin:   true,
+			limit:   &domain.RateLimit{Requests: 5, Period: time.Minute},
+			handle:  performWith(s.service.VerifyProvider)},
+		{method: "GET", path: "/provider/{provider_id}/service-areas/", name: "list-service-areas", tag: "providers",
+			summary: "List the postal code ranges served by a provider, a provider without any serves every postal code",
+			cache:   "public, max-age=60",
+			handle:  performWith(s.service.ListServiceAreas)},
+		{method: "POST", path: "/provider/{provider_id}/service-areas/", name: "import-service-areas", tag: "providers",
+			summary: "Import the service areas of a provider from a CSV, replacing its areas with replace=true",
+			admin:   true,
+			limit:   &domain.RateLimit{Requests: 5, Period: time.Minute},
+			handle:  performWith(s.service.ImportServiceAreas)},
+		{method: "GET", path: "/provider/{provider_id}/tariffs/", name: "list-tariffs", tag: "providers",
+			summary: "List the prices of a provider by the weight of the parcel",
+			cache:   "public, max-age=60",
+			handle:  performWith(s.service.ListTariffs)},
+		{method: "PUT", path: "/provider/{provider_id}/tariffs/", name: "update-tariffs", tag: "providers",
+			summary: "Replace the tariffs of a provider, a provider without tariffs is quoted through its quote url",
+			admin:   true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performWith(s.service.UpdateTariffs)},
+
+		{method: "POST", path: "/quotes/", name: "create-quote", tag: "quotes",
+			summary: "Price a parcel with every provider serving the postal codes, ranked from the cheapest",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			created: true,
+			handle:  performWith(s.service.CreateQuote)},
+
+		{method: "POST", path: "/customer/", name: "create-customer", tag: "customers",
+			summary: "Register a customer",
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			created: true,
+			handle:  performWith(s.service.CreateCustomer)},
+		{method: "POST", path: "/customer/token/", name: "customer-token", tag: "customers",
+			summary: "Issue an access token for a customer",
+			limit:   &domain.RateLimit{Requests: 5, Period: time.Minute},
+			handle:  performWith(s.service.GetCustomerToken)},
+		{method: "GET", path: "/customer/me/", name: "get-profile", tag: "customers",
+			summary: "Get the profile of the authorized customer",
+			auth:    true, cache: "private, no-cache",
+			handle: perform(s.service.GetProfile)},
+		{method: "PATCH", path: "/customer/me/", name: "update-profile", tag: "customers",
+			summary: "Update the profile of the authorized customer, a new phone number is applied once verified",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performWith(s.service.UpdateProfile)},
+		{method: "POST", path: "/customer/me/phone/verify/", name: "verify-phone-number", tag: "customers",
+			summary: "Apply the pending phone number of the authorized customer with the code sent to it",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 5, Period: time.Minute},
+			handle:  performWith(s.service.VerifyPhoneNumber)},
+		{method: "GET", path: "/customer/me/addresses/", name: "list-addresses", tag: "addresses",
+			summary: "List the saved addresses of the authorized customer, the default address first",
+			auth:    true, cache: "private, no-cache",
+			handle: perform(s.service.ListAddresses)},
+		{method: "POST", path: "/customer/me/addresses/", name: "create-address", tag: "addresses",
+			summary: "Save an address of the authorized customer, its first address is the default one",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			created: true, location: "/customer/me/addresses/{address_id}/",
+			handle: performWith(s.service.CreateAddress)},
+		{method: "GET", path: "/customer/me/addresses/{address_id}/", name: "get-address", tag: "addresses",
+			summary: "Get a saved address of the authorized customer",
+			auth:    true, cache: "private, no-cache",
+			handle: performWith(s.service.GetAddress)},
+		{method: "PATCH", path: "/customer/me/addresses/{address_id}/", name: "update-address", tag: "addresses",
+			summary: "Update a saved address of the authorized customer, orders keep the address they were created with",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performWith(s.service.UpdateAddress)},
+		{method: "DELETE", path: "/customer/me/addresses/{address_id}/", name: "delete-address", tag: "addresses",
+			summary: "Delete a saved address of the authorized customer and return it",
+			auth:    true,
+			handle:  performWith(s.service.DeleteAddress)},
+
+		{method: "POST", path: "/order/", name: "create-order", tag: "orders",
+			summary: "Create an order sent by the authorized customer",
+			auth:    true, idempotent: true,
+			limit:   &domain.RateLimit{Requests: 30, Period: time.Minute},
+			created: true, location: "/order/{order_id}/",
+			handle: performWith(s.service.CreateOrder)},
+		{method: "GET", path: "/orders/incoming/", name: "list-incoming-orders", tag: "orders",
+			summary: "List the orders sent to the authorized customer, the latest first",
+			auth:    true, cache: "private, no-cache",
+			handle: performWith(s.service.ListIncomingOrders)},
+		{method: "GET", path: "/order/{order_id}/", name: "get-order", tag: "orders",
+			summary: "Get an order sent by or to the authorized customer",
+			auth:    true, cache: "private, no-cache",
+			handle: performWith(s.service.GetOrder)},
+		{method: "GET", path: "/order/{order_id}/delivery-slots/", name: "list-delivery-slots", tag: "orders",
+			summary: "List the slots the receiver can reschedule the delivery of an order to, until it is picked up",
+			auth:    true, cache: "private, no-cache",
+			handle: performWith(s.service.ListDeliverySlots)},
+		{method: "PATCH", path: "/order/{order_id}/delivery/", name: "update-delivery", tag: "orders",
+			summary: "Reschedule, hold at location or instruct the delivery of an order sent to the authorized customer, until it is picked up",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performWith(s.service.UpdateDelivery)},
+	}
+}
+
+// routesV2 serves the orders with the DTOs of v2, the other routes are the same as v1.
+func (s *Server) routesV2() []route {
+	return replaceRoutes(s.routesV1(),
+		route{method: "POST", path: "/order/", name: "create-order", tag: "orders",
+			summary: "Create an order sent by the authorized customer",
+			auth:    true, idempotent: true,
+			limit:   &domain.RateLimit{Requests: 30, Period: time.Minute},
+			created: true, location: "/order/{order_id}/",
+			handle: performAs(s.service.CreateOrder, (*v2.OrderCreateRequest).ToDomain, v2.NewOrder)},
+		route{method: "GET", path: "/orders/incoming/", name: "list-incoming-orders", tag: "orders",
+			summary: "List the orders sent to the authorized customer, the latest first",
+			auth:    true, cache: "private, no-cache",
+			handle: performAs(s.service.ListIncomingOrders, (*v2.OrderListRequest).ToDomain, v2.NewOrderPage)},
+		route{method: "GET", path: "/order/{order_id}/", name: "get-order", tag: "orders",
+			summary: "Get an order sent by or to the authorized customer",
+			auth:    true, cache: "private, no-cache",
+			handle: performAs(s.service.GetOrder, (*v2.OrderGetRequest).ToDomain, v2.NewOrder)},
+		route{method: "PATCH", path: "/order/{order_id}/delivery/", name: "update-delivery", tag: "orders",
+			summary: "Reschedule, hold at location or instruct the delivery of an order sent to the authorized customer, until it is picked up",
+			auth:    true,
+			limit:   &domain.RateLimit{Requests: 10, Period: time.Minute},
+			handle:  performAs(s.service.UpdateDelivery, (*v2.OrderDeliveryUpdateRequest).ToDomain, v2.NewOrder)},
+	)
+}
+
+// replaceRoutes returns the routes with the ones named like the replacements replaced.
+func replaceRoutes(routes []route, replacements ...route) []route {
+	for _, replacement := range replacements {
+		for i := range routes {
+			if routes[i].name == replacement.name {
+				routes[i] = replacement
+			}
+		}
+	}
+	return routes
+}
+
+// Handler returns the router of the routes, the OpenAPI document and the docs page, wrapped by the middlewares.
+func (s *Server) Handler() http.Handler {
 	stack := middlewares.MiddlewareStack(
 		middlewares.Logging,
 		middlewares.JWTMiddleware,
+		middlewares.Tracing,
+		middlewares.RequestID,
 	)
+	limit := middlewares.RateLimiter(s.rateLimitStore)
+	idempotent := middlewares.Idempotency(s.idempotencyStore)
 
-	router.HandleFunc("GET /api/health/", makeHTTPHandleFunc(perform(s.service.HealthCheck)))
+	handler := func(prefix string, r route) http.Handler {
+		handle := r.handle.serve
+		if r.created {
+			handle = withCreated(prefix+r.location, handle)
+		}
+		if r.cache != "" {
+			handle = withCaching(r.cache, handle)
+		}
 
-	router.HandleFunc("GET /api/providers/", makeHTTPHandleFunc(perform(s.service.GetProviders)))
-	router.HandleFunc("GET /api/providers/report/", makeHTTPHandleFunc(perform(s.service.GetProvidersMeanDelTime)))
-	router.HandleFunc("POST /api/provider/", makeHTTPHandleFunc(performWith(s.service.CreateProvider)))
+		var handler http.Handler = makeHTTPHandleFunc(handle)
+		if r.auth {
+			handler = makeHTTPHandleFuncWithAuth(handle)
+		}
+		if r.admin {
+			handler = middlewares.AdminAuth(handler)
+		}
+		if r.idempotent {
+			handler = idempotent(handler)
+		}
+		if r.limit != nil {
+			handler = limit(r.name, r.limit.Requests, r.limit.Period)(handler)
+		}
+		return handler
+	}
 
-	router.HandleFunc("POST /api/customer/", makeHTTPHandleFunc(performWith(s.service.CreateCustomer)))
-	router.HandleFunc("POST /api/customer/token/", makeHTTPHandleFunc(performWith(s.service.GetCustomerToken)))
+	router := http.NewServeMux()
+	versions := s.versions()
+	for _, v := range versions {
+		for _, r := range v.routes {
+			h := handler(v.prefix(), r)
+			router.Handle(r.method+" "+v.prefix()+r.path, h)
+			if v.name != legacyVersion {
+				continue
+			}
 
-	router.HandleFunc("POST /api/order/", makeHTTPHandleFuncWithAuth(performWith(s.service.CreateOrder)))
-	router.HandleFunc("GET /api/order/{order_id}/", makeHTTPHandleFuncWithAuth(performWith(s.service.GetOrder)))
+			deprecated := middlewares.Deprecated("/api", v.prefix(), configs.LegacyRoutesSunset)
+			switch configs.LegacyRoutes {
+			case "alias":
+				router.Handle(r.method+" /api"+r.path, deprecated(h))
+			case "redirect":
+				router.Handle(r.method+" /api"+r.path, deprecated(middlewares.Redirect("/api", v.prefix())))
+			}
+		}
+	}
+
+	document := newDocument(versions)
+	router.HandleFunc("GET "+openAPIPath, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(document)
+	})
+	router.Handle("GET "+docsPath, openapi.DocsHandler(document.Info.Title, openAPIPath))
+
+	return stack(router)
+}
+
+// checkLegacyRoutes checks LEGACY_ROUTES, so a typo stops the server from starting instead of dropping
+// every unversioned path.
+func checkLegacyRoutes() error {
+	switch configs.LegacyRoutes {
+	case "alias", "redirect", "off":
+		return nil
+	}
+	return fmt.Errorf("unknown LEGACY_ROUTES %q, must be one of alias, redirect or off", configs.LegacyRoutes)
+}
+
+// checkRequests checks the validation tags of the request types of the routes, so a typo in a tag stops the
+// server from starting instead of skipping the rule in every request.
+func (s *Server) checkRequests() error {
+	for _, v := range s.versions() {
+		for _, r := range v.routes {
+			if r.handle.request == nil {
+				continue
+			}
+			if err := domain.CheckTags(r.handle.request); err != nil {
+				return fmt.Errorf("route %s of version %q: %w", r.name, v.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) Run() {
+	if err := s.checkRequests(); err != nil {
+		slog.Error("invalid request validation tags", "error", err)
+		os.Exit(1)
+	}
+	if err := checkLegacyRoutes(); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
 	server := http.Server{
 		Addr:    s.listenAddr,
-		Handler: stack(router),
+		Handler: s.Handler(),
 	}
 
-	log.Println("API Server Running on:", s.listenAddr)
+	slog.Info("API server running", "address", s.listenAddr)
 	if err := server.ListenAndServe(); err != nil {
-		log.Fatal(err)
+		slog.Error("API server stopped", "error", err)
+		os.Exit(1)
 	}
 }
 
-func performWith[T any, S domain.Request](f func(ctx context.Context, body S) (T, *errors.AppError)) responseFunc {
-	return func(request *http.Request) *models.Response {
-		var b S
-		bodyType := reflect.TypeOf(b)
-		body := reflect.New(bodyType.Elem()).Interface().(S)
-
-		var err *errors.AppError
-		if request.Method == "GET" {
-			err = body.UnmarshalPathValue(request)
-		} else {
-			err = body.UnmarshalBody(request)
-		}
+// performAs binds the request to the DTO R of an API version and converts it to the request
+// of the service, then converts the result of the service to the DTO D of the version.
+func performAs[R any, S any, T any, D any](f func(ctx context.Context, request *S) (T, *errors.AppError), toRequest func(*R) *S, toResult func(T) D) handler {
+	return performWith(func(ctx context.Context, request *R) (D, *errors.AppError) {
+		result, err := f(ctx, toRequest(request))
 		if err != nil {
+			var zero D
+			return zero, err
+		}
+		return toResult(result), nil
+	})
+}
+
+// performWith binds the path values, query parameters and body of the request to S before calling f.
+func performWith[T any, S any](f func(ctx context.Context, request *S) (T, *errors.AppError)) handler {
+	serve := func(request *http.Request) *models.Response {
+		body := new(S)
+		if err := domain.Bind(body, request); err != nil {
 			return models.ReturnErrorResp(request.Context(), err)
 		}
 		result, err := f(request.Context(), body)
@@ -79,10 +427,11 @@ func performWith[T any, S domain.Request](f func(ctx context.Context, body S) (T
 		}
 		return resp
 	}
+	return handler{request: reflect.TypeFor[S](), response: reflect.TypeFor[T](), serve: serve}
 }
 
-func perform[T any](f func(ctx context.Context) (T, *errors.AppError)) responseFunc {
-	return func(request *http.Request) *models.Response {
+func perform[T any](f func(ctx context.Context) (T, *errors.AppError)) handler {
+	serve := func(request *http.Request) *models.Response {
 		result, err := f(request.Context())
 		resp := models.ReturnResp(request.Context(), result)
 		if err != nil {
@@ -90,6 +439,60 @@ func perform[T any](f func(ctx context.Context) (T, *errors.AppError)) responseF
 		}
 		return resp
 	}
+	return handler{response: reflect.TypeFor[T](), serve: serve}
+}
+
+// withHealthStatus responds with 503 when the returned health report is not ready.
+func withHealthStatus(h handler) handler {
+	serve := h.serve
+	h.serve = func(request *http.Request) *models.Response {
+		resp := serve(request)
+		if report, ok := resp.Result.(*domain.HealthReport); ok && !report.Ready() {
+			resp.Code = http.StatusServiceUnavailable
+		}
+		return resp
+	}
+	return h
+}
+
+// withCreated responds with 201 to successful requests, along with the location of the created object.
+func withCreated(location string, f responseFunc) responseFunc {
+	return func(request *http.Request) *models.Response {
+		resp := f(request)
+		if resp.Code != http.StatusOK {
+			return resp
+		}
+		resp.Code = http.StatusCreated
+		if location != "" {
+			if id := reflect.Indirect(reflect.ValueOf(resp.Result)).FieldByName("ID"); id.IsValid() {
+				resp.Headers.Set("Location", pathWildcard.ReplaceAllString(location, fmt.Sprint(id.Interface())))
+			}
+		}
+		return resp
+	}
+}
+
+// withCaching sets the Cache-Control header of the responses. Successful responses that may be
+// stored get an ETag too, and conditional requests matching it are answered with 304.
+func withCaching(cacheControl string, f responseFunc) responseFunc {
+	return func(request *http.Request) *models.Response {
+		resp := f(request)
+		if resp.Code != http.StatusOK {
+			resp.Headers.Set("Cache-Control", "no-store")
+			return resp
+		}
+		resp.Headers.Set("Cache-Control", cacheControl)
+		if cacheControl == "no-store" {
+			return resp
+		}
+		if etag, e := resp.ETag(); e == nil {
+			resp.Headers.Set("ETag", etag)
+			if resp.NotModified(request) {
+				resp.Code = http.StatusNotModified
+			}
+		}
+		return resp
+	}
 }
 
 func makeHTTPHandleFuncWithAuth(f responseFunc) http.HandlerFunc {
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistic-app/internal/adapters/http/middlewares"
+	"logistic-app/internal/adapters/http/models"
+	v2 "logistic-app/internal/adapters/http/models/v2"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubService only provides the methods the routes refer to, calling them panics.
+type stubService struct {
+	ports.Service
+}
+
+type providersService struct {
+	stubService
+	providers []*domain.Provider
+}
+
+func (s *providersService) GetProviders(ctx context.Context, request *domain.ProviderListRequest) (*domain.Page[*domain.Provider], *errors.AppError) {
+	end := len(s.providers)
+	if request.Limit > 0 {
+		end = min(int(request.Offset+request.Limit), end)
+	}
+	return domain.NewPage(s.providers[min(int(request.Offset), end):end], request.Pagination(), int64(len(s.providers))), nil
+}
+
+type testEnvelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *errors.Problem `json:"error"`
+	Meta  *models.Meta    `json:"meta"`
+}
+
+func TestEnvelope(t *testing.T) {
+	service := &providersService{providers: []*domain.Provider{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}}
+	handler := NewServer(service, nil, nil).Handler()
+	get := func(url string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", url, http.NoBody)
+		for key, values := range header {
+			request.Header[key] = values
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("Data With Pagination", func(t *testing.T) {
+		recorder := get("/api/v1/providers/?limit=2", nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "public, max-age=60", recorder.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, recorder.Header().Get("ETag"))
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var envelope testEnvelope
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+		var providers []*domain.Provider
+		require.NoError(t, json.Unmarshal(envelope.Data, &providers))
+		assert.Equal(t, 2, len(providers))
+		assert.Empty(t, envelope.Error)
+		assert.Equal(t, recorder.Header().Get("X-Request-ID"), envelope.Meta.RequestID)
+		assert.False(t, envelope.Meta.Timestamp.IsZero())
+		assert.Equal(t, &domain.PageInfo{Limit: 2, Offset: 0, Total: 3}, envelope.Meta.Pagination)
+	})
+
+	t.Run("All Providers Without A Limit", func(t *testing.T) {
+		recorder := get("/api/v1/providers/", nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var envelope testEnvelope
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+		var providers []*domain.Provider
+		require.NoError(t, json.Unmarshal(envelope.Data, &providers))
+		assert.Equal(t, 3, len(providers))
+		assert.Equal(t, &domain.PageInfo{Limit: 0, Offset: 0, Total: 3}, envelope.Meta.Pagination)
+	})
+
+	t.Run("Not Modified", func(t *testing.T) {
+		etag := get("/api/v1/providers/?limit=2", nil).Header().Get("ETag")
+
+		recorder := get("/api/v1/providers/?limit=2", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+
+		recorder = get("/api/v1/providers/?limit=2&offset=1", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		recorder := get("/api/v1/providers/?limit=1000", nil)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var envelope testEnvelope
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+		assert.Equal(t, "null", string(envelope.Data))
+		assert.Equal(t, errors.CodeValidationFailed, envelope.Error.Code)
+		assert.Equal(t, "urn:request-id:"+envelope.Meta.RequestID, envelope.Error.Instance)
+	})
+}
+
+func TestWithCreated(t *testing.T) {
+	create := func(err *errors.AppError) responseFunc {
+		return func(request *http.Request) *models.Response {
+			if err != nil {
+				return models.ReturnErrorResp(request.Context(), err)
+			}
+			return models.ReturnResp(request.Context(), &domain.Order{ID: 7})
+		}
+	}
+	request := httptest.NewRequest("POST", "/api/order/", http.NoBody)
+
+	t.Run("Created", func(t *testing.T) {
+		resp := withCreated("/api/order/{order_id}/", create(nil))(request)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, "/api/order/7/", resp.Headers.Get("Location"))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		resp := withCreated("/api/order/{order_id}/", create(errors.BadRequest("invalid")))(request)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Empty(t, resp.Headers.Get("Location"))
+	})
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	service := &providersService{providers: []*domain.Provider{{ID: 1, Name: "a"}}}
+	legacyRoutes := configs.LegacyRoutes
+	defer func() { configs.LegacyRoutes = legacyRoutes }()
+	get := func(mode, url string) *httptest.ResponseRecorder {
+		configs.LegacyRoutes = mode
+		recorder := httptest.NewRecorder()
+		NewServer(service, nil, nil).Handler().ServeHTTP(recorder, httptest.NewRequest("GET", url, http.NoBody))
+		return recorder
+	}
+
+	t.Run("Alias", func(t *testing.T) {
+		recorder := get("alias", "/api/providers/?limit=5")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+		assert.Equal(t, `</api/v1/providers/>; rel="successor-version"`, recorder.Header().Get("Link"))
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		recorder := get("redirect", "/api/providers/?limit=5")
+		assert.Equal(t, http.StatusPermanentRedirect, recorder.Code)
+		assert.Equal(t, "/api/v1/providers/?limit=5", recorder.Header().Get("Location"))
+		assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("off", "/api/providers/").Code)
+	})
+
+	t.Run("Versions Are Not Deprecated", func(t *testing.T) {
+		for _, url := range []string{"/api/v1/providers/", "/api/v2/providers/"} {
+			recorder := get("alias", url)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Empty(t, recorder.Header().Get("Deprecation"))
+		}
+	})
+
+	t.Run("Unknown Mode", func(t *testing.T) {
+		for _, mode := range []string{"alias", "redirect", "off"} {
+			configs.LegacyRoutes = mode
+			assert.NoError(t, checkLegacyRoutes())
+		}
+		configs.LegacyRoutes = "aliass"
+		assert.Error(t, checkLegacyRoutes())
+	})
+}
+
+type ordersService struct {
+	stubService
+}
+
+func (s *ordersService) GetOrder(ctx context.Context, request *domain.OrderGetRequest) (*domain.Order, *errors.AppError) {
+	if request.OrderID != 3 {
+		return nil, errors.NotFoundError(fmt.Errorf("order not found"))
+	}
+	name := "mahsa"
+	return &domain.Order{
+		ID:         3,
+		ProviderID: 2,
+		Provider:   &domain.Provider{ID: 2, Name: "test-provider"},
+		SenderID:   5,
+		ReceiverID: 8,
+		Receiver:   &domain.Customer{ID: 8, Name: &name},
+		Status:     domain.GetOrderStatus().Delivered,
+	}, nil
+}
+
+func TestPerformAs(t *testing.T) {
+	handle := performAs((&ordersService{}).GetOrder, (*v2.OrderGetRequest).ToDomain, v2.NewOrder)
+	get := func(orderID string) *models.Response {
+		request := httptest.NewRequest("GET", "/api/v2/order/"+orderID+"/", http.NoBody)
+		request.SetPathValue("order_id", orderID)
+		return handle.serve(request)
+	}
+
+	t.Run("Converted Result", func(t *testing.T) {
+		resp := get("3")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		order, ok := resp.Result.(*v2.Order)
+		require.True(t, ok)
+		assert.Equal(t, "test-provider", *order.Provider.Name)
+		assert.Empty(t, order.Sender.Name)
+		assert.Equal(t, "mahsa", *order.Receiver.Name)
+		assert.Equal(t, "DELIVERED", order.Status)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		resp := get("4")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		resp := get("abc")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestRequestValidationTags(t *testing.T) {
+	assert.NoError(t, NewServer(stubService{}, nil, nil).checkRequests())
+}
+
+func TestAdminRoutes(t *testing.T) {
+	defer func(token string) { configs.AdminToken = token }(configs.AdminToken)
+	configs.AdminToken = "admin-secret"
+	server := NewServer(stubService{}, middlewares.NewMemoryRateLimitStore(), nil)
+	handler := server.Handler()
+
+	for _, v := range server.versions() {
+		for _, r := range v.routes {
+			if !r.admin {
+				continue
+			}
+			path := v.prefix() + pathWildcard.ReplaceAllString(r.path, "1")
+			t.Run(r.method+" "+path, func(t *testing.T) {
+				// the stub service panics when the request gets through
+				request := httptest.NewRequest(r.method, path, http.NoBody)
+				request.Header.Set(middlewares.AdminTokenHeader, "wrong")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			})
+		}
+	}
+}
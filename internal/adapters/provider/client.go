@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/telemetry"
+	"net/http"
+	"net/url"
+)
+
+// maxResponseSize is the largest response body read from a provider, larger bodies are refused.
+const maxResponseSize = 1 << 20
+
+// Client talks to the providers over their url, the status of the parcels is read with a GET,
+// shipments are booked with a POST of the shipment and their deliveries are changed with a PATCH.
+// Urls reaching the network of the server are refused unless PROVIDER_ALLOW_PRIVATE_NETWORKS is set.
+type Client struct {
+	client *http.Client
+}
+
+func NewClient() *Client {
+	return newClient(configs.ProviderAllowPrivateNetworks)
+}
+
+func newClient(allowPrivate bool) *Client {
+	return &Client{
+		client: &http.Client{
+			Transport: telemetry.NewTransport(newTransport(allowPrivate)),
+			Timeout:   configs.ProviderRequestTimeout,
+		},
+	}
+}
+
+// Ping reports whether the provider responds, any response below 500 counts.
+func (c *Client) Ping(ctx context.Context, provider *domain.Provider) *errors.AppError {
+	resp, err := c.do(ctx, http.MethodGet, provider.Url, nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errors.InternalServerError(fmt.Errorf("provider %s responded with %d", provider.Name, resp.StatusCode))
+	}
+	return nil
+}
+
+// GetStatuses reads the statuses of the parcel, sending its tracking number as the reference.
+func (c *Client) GetStatuses(ctx context.Context, provider *domain.Provider, trackingNumber string) (*domain.ProviderUrlResponse, *errors.AppError) {
+	target, err := withReference(provider.Url, trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data *domain.ProviderUrlResponse
+	if err = decode(provider, resp, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// BookShipment sends the shipment to the provider, with the contacts and the addresses kept on the order.
+func (c *Client) BookShipment(ctx context.Context, provider *domain.Provider, shipment *domain.Shipment) *errors.AppError {
+	body, e := json.Marshal(shipment)
+	if e != nil {
+		return errors.InternalServerError(e)
+	}
+	resp, err := c.do(ctx, http.MethodPost, provider.Url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.InternalServerError(fmt.Errorf("provider %s rejected the shipment of order %d with %d",
+			provider.Name, shipment.OrderID, resp.StatusCode))
+	}
+	return nil
+}
+
+// UpdateDelivery sends the delivery changed by the receiver with a PATCH to the url of the provider.
+func (c *Client) UpdateDelivery(ctx context.Context, provider *domain.Provider, delivery *domain.ShipmentDelivery) *errors.AppError {
+	body, e := json.Marshal(delivery)
+	if e != nil {
+		return errors.InternalServerError(e)
+	}
+	resp, err := c.do(ctx, http.MethodPatch, provider.Url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.New(http.StatusBadGateway, errors.CodeProviderRejected, "the provider rejected the change of the delivery",
+			fmt.Errorf("provider %s rejected the delivery of order %d with %d", provider.Name, delivery.OrderID, resp.StatusCode))
+	}
+	return nil
+}
+
+// Quote posts the request to the quote url of the provider and reads the price it responds with.
+func (c *Client) Quote(ctx context.Context, provider *domain.Provider, request *domain.ProviderQuoteRequest) (*domain.ProviderQuote, *errors.AppError) {
+	if provider.QuoteUrl == nil {
+		return nil, errors.InternalServerError(fmt.Errorf("provider %s has no quote url", provider.Name))
+	}
+	body, e := json.Marshal(request)
+	if e != nil {
+		return nil, errors.InternalServerError(e)
+	}
+	resp, err := c.do(ctx, http.MethodPost, *provider.QuoteUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var quote *domain.ProviderQuote
+	if err = decode(provider, resp, &quote); err != nil {
+		return nil, err
+	}
+	if quote == nil || quote.Price <= 0 {
+		return nil, errors.InternalServerError(fmt.Errorf("provider %s responded without a price", provider.Name))
+	}
+	return quote, nil
+}
+
+// Verify reads the statuses of the provider, sending the reference of a test order when one is given,
+// and checks they hold what GetStatuses is expected to return.
+func (c *Client) Verify(ctx context.Context, provider *domain.Provider, reference string) *errors.AppError {
+	target, err := withReference(provider.Url, reference)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var data *domain.ProviderUrlResponse
+	if err = decode(provider, resp, &data); err != nil {
+		return err
+	}
+	if e := data.Validate(); e != nil {
+		return errors.InternalServerError(fmt.Errorf("provider %s responded with an unexpected body: %w", provider.Name, e))
+	}
+	return nil
+}
+
+// withReference adds the reference to the query of the url, unless it is empty.
+func withReference(rawUrl, reference string) (string, *errors.AppError) {
+	target, e := url.Parse(rawUrl)
+	if e != nil {
+		return "", errors.InternalServerError(e)
+	}
+	if reference != "" {
+		query := target.Query()
+		query.Set("reference", reference)
+		target.RawQuery = query.Encode()
+	}
+	return target.String(), nil
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body []byte) (*http.Response, *errors.AppError) {
+	req, e := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if e != nil {
+		return nil, errors.InternalServerError(e)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, e := c.client.Do(req)
+	if e != nil {
+		return nil, errors.InternalServerError(e)
+	}
+	return resp, nil
+}
+
+func decode(provider *domain.Provider, resp *http.Response, v any) *errors.AppError {
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.InternalServerError(fmt.Errorf("provider %s responded with %d", provider.Name, resp.StatusCode))
+	}
+	data, e := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if e != nil {
+		return errors.InternalServerError(e)
+	}
+	if len(data) > maxResponseSize {
+		return errors.InternalServerError(fmt.Errorf("provider %s responded with more than %d bytes", provider.Name, maxResponseSize))
+	}
+	if e = json.Unmarshal(data, v); e != nil {
+		return errors.InternalServerError(e)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Verify(t *testing.T) {
+	var reference string
+	body := `{"message": "ok", "data": [{"status": "1"}, {"status": "2"}, {"status": "3"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reference = r.URL.Query().Get("reference")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newClient(true)
+	provider := &domain.Provider{Name: "test-provider", Url: server.URL + "/status"}
+
+	t.Run("Passes With The Expected Statuses", func(t *testing.T) {
+		assert.Empty(t, client.Verify(context.Background(), provider, "TEST-1"))
+		assert.Equal(t, "TEST-1", reference)
+	})
+
+	t.Run("Fails With An Unexpected Body", func(t *testing.T) {
+		body = `{"message": "ok", "data": [{"status": "9"}]}`
+		assert.NotEmpty(t, client.Verify(context.Background(), provider, ""))
+		assert.Empty(t, reference)
+
+		body = `{"message": "ok"}`
+		assert.NotEmpty(t, client.Verify(context.Background(), provider, ""))
+
+		body = `<html></html>`
+		assert.NotEmpty(t, client.Verify(context.Background(), provider, ""))
+	})
+}
+
+func TestClient(t *testing.T) {
+	var booked *domain.Shipment
+	var delivery *domain.ShipmentDelivery
+	var reference string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reference = r.URL.Query().Get("reference")
+		switch r.Method {
+		case http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&booked)
+		case http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(&delivery)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message": "ok", "data": [{"status": "1"}]}`))
+	}))
+	defer server.Close()
+
+	client := newClient(true)
+	provider := &domain.Provider{Name: "test-provider", Url: server.URL}
+
+	t.Run("Books The Snapshot Of The Order", func(t *testing.T) {
+		name := "mahsa"
+		order := &domain.Order{ID: 3, Parcel: domain.Parcel{Weight: 2, WeightUnit: "kg", Pieces: 1, Fragile: true}}
+		order.Parcels = []*domain.OrderParcel{{TrackingNumber: "7K2M9QX4BD1R", Parcel: order.Parcel}, {TrackingNumber: "X4BD1R7K2M9Q"}}
+		order.SetPickup(&domain.Customer{Name: &name, PhoneNumber: "+989121234567"}, &domain.Address{Street: "Valiasr St", City: "Tehran", PostalCode: "1234567890"})
+		order.SetDropoff(&domain.Customer{PhoneNumber: "+989351234567"}, &domain.Address{Street: "somewhere", PostalCode: "6372687"})
+
+		err := client.BookShipment(context.Background(), provider, domain.NewShipment(order))
+		assert.Empty(t, err)
+		assert.Equal(t, uint(3), booked.OrderID)
+		assert.Equal(t, name, *booked.Pickup.ContactName)
+		assert.Equal(t, "Valiasr St, Tehran", booked.Pickup.Address)
+		assert.Equal(t, "+989351234567", booked.Dropoff.ContactPhone)
+		assert.Equal(t, "6372687", booked.Dropoff.PostalCode)
+		assert.Equal(t, 2.0, booked.Parcel.Weight)
+		assert.True(t, booked.Parcel.Fragile)
+		assert.Len(t, booked.Parcels, 2)
+		assert.Equal(t, "7K2M9QX4BD1R", booked.Parcels[0].TrackingNumber)
+		assert.Equal(t, 2.0, booked.Parcels[0].Parcel.Weight)
+		assert.Nil(t, booked.Parcels[1].Parcel)
+	})
+
+	t.Run("Reads Statuses Of A Parcel", func(t *testing.T) {
+		data, err := client.GetStatuses(context.Background(), provider, "7K2M9QX4BD1R")
+		assert.Empty(t, err)
+		assert.Equal(t, "1", data.Data[0].StatusNumber)
+		assert.Equal(t, "7K2M9QX4BD1R", reference)
+	})
+
+	t.Run("Sends The Delivery Of The Receiver", func(t *testing.T) {
+		start := time.Date(2025, 4, 23, 13, 0, 0, 0, time.UTC)
+		end := start.Add(4 * time.Hour)
+		order := &domain.Order{ID: 3, DeliverySlotStart: &start, DeliverySlotEnd: &end}
+		order.Parcels = []*domain.OrderParcel{{TrackingNumber: "7K2M9QX4BD1R"}}
+
+		err := client.UpdateDelivery(context.Background(), provider, domain.NewShipmentDelivery(order))
+		assert.Empty(t, err)
+		assert.Equal(t, uint(3), delivery.OrderID)
+		assert.Equal(t, []string{"7K2M9QX4BD1R"}, delivery.TrackingNumbers)
+		assert.True(t, start.Equal(delivery.Slot.Start))
+		assert.False(t, delivery.HoldAtLocation)
+	})
+
+	t.Run("Fails On Server Errors", func(t *testing.T) {
+		status = http.StatusBadGateway
+		defer func() { status = http.StatusOK }()
+
+		assert.NotEmpty(t, client.Ping(context.Background(), provider))
+		assert.NotEmpty(t, client.BookShipment(context.Background(), provider, &domain.Shipment{}))
+		err := client.UpdateDelivery(context.Background(), provider, &domain.ShipmentDelivery{})
+		assert.Equal(t, http.StatusBadGateway, err.Code)
+		assert.Equal(t, errors.CodeProviderRejected, err.ApiErr.Code)
+		_, err = client.GetStatuses(context.Background(), provider, "")
+		assert.NotEmpty(t, err)
+	})
+}
+
+func TestClient_Quote(t *testing.T) {
+	var asked *domain.ProviderQuoteRequest
+	body := `{"price": 120000, "delivery_days": 2}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&asked)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newClient(true)
+	quoteUrl := server.URL + "/quote"
+	provider := &domain.Provider{Name: "test-provider", Url: server.URL, QuoteUrl: &quoteUrl}
+	request := &domain.ProviderQuoteRequest{PickupPostalCode: "1234567890", Parcel: &domain.Parcel{Weight: 2}, ChargeableWeight: 2}
+
+	t.Run("Reads The Price", func(t *testing.T) {
+		quote, err := client.Quote(context.Background(), provider, request)
+		assert.Empty(t, err)
+		assert.Equal(t, int64(120000), quote.Price)
+		assert.Equal(t, float32(2), quote.DeliveryDays)
+		assert.Equal(t, "1234567890", asked.PickupPostalCode)
+	})
+
+	t.Run("Fails Without A Price", func(t *testing.T) {
+		body = `{"delivery_days": 2}`
+		_, err := client.Quote(context.Background(), provider, request)
+		assert.NotEmpty(t, err)
+
+		_, err = client.Quote(context.Background(), &domain.Provider{Name: "test-provider", Url: server.URL}, request)
+		assert.NotEmpty(t, err)
+	})
+
+	t.Run("Fails With A Body Over The Limit", func(t *testing.T) {
+		body = `{"price": 120000, "delivery_days": 2, "note": "` + strings.Repeat("x", maxResponseSize) + `"}`
+		_, err := client.Quote(context.Background(), provider, request)
+		assert.NotEmpty(t, err)
+	})
+}
+
+func TestClient_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"message": "ok", "data": [{"status": "1"}]}`))
+	}))
+	defer server.Close()
+	provider := &domain.Provider{Name: "test-provider", Url: server.URL + "/status"}
+
+	err := newClient(false).Verify(context.Background(), provider, "")
+	if assert.NotEmpty(t, err) {
+		assert.True(t, stdErrors.Is(err.Err, errBlockedAddress))
+	}
+	assert.Empty(t, newClient(true).Verify(context.Background(), provider, ""))
+
+	for address, internal := range map[string]bool{
+		"127.0.0.1":        true,
+		"::1":              true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"fe80::1":          true,
+		"fd00::1":          true,
+		"0.0.0.0":          true,
+		"::ffff:127.0.0.1": true,
+		"8.8.8.8":          false,
+		"2001:4860::8888":  false,
+	} {
+		assert.Equal(t, internal, isInternal(net.ParseIP(address)), address)
+	}
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errBlockedAddress is returned for provider urls reaching the network of the server.
+var errBlockedAddress = errors.New("the url resolves to a loopback, private or link-local address")
+
+// newTransport returns a transport which only connects to public addresses, unless allowPrivate is set. The address
+// is checked after the host is resolved, on every connection including the ones of redirects, so neither a host
+// resolving to an internal address nor a redirect to one reaches the network of the server. Proxies are not used
+// as they would be the only address checked.
+func newTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, e := net.SplitHostPort(address)
+			if e != nil {
+				return e
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternal(ip) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// isInternal reports whether the address is in the network of the server rather than on the internet.
+func isInternal(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Address is a saved address of a customer, at most one address of a customer is its default.
+type Address struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID uint      `json:"customer_id" gorm:"not null;index;uniqueIndex:idx_addresses_label;uniqueIndex:idx_addresses_default,where:is_default"`
+	Customer   *Customer `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Label      string    `json:"label" gorm:"size:50;not null;uniqueIndex:idx_addresses_label"`
+	IsDefault  bool      `json:"is_default" gorm:"not null;default:false"`
+	City       string    `json:"city" gorm:"size:100;not null"`
+	Street     string    `json:"street" gorm:"size:500;not null"`
+	Unit       *string   `json:"unit" gorm:"size:50"`
+	PostalCode string    `json:"postal_code" gorm:"size:10;not null"`
+	Latitude   *float64  `json:"latitude"`
+	Longitude  *float64  `json:"longitude"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// Line returns the address in a single line, like "Valiasr St, Unit 4, Tehran".
+func (a *Address) Line() string {
+	parts := []string{a.Street}
+	if a.Unit != nil && *a.Unit != "" {
+		parts = append(parts, *a.Unit)
+	}
+	if a.City != "" {
+		parts = append(parts, a.City)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type AddressCreateRequest struct {
+	Label      string   `json:"label" validate:"required,max=50"`
+	IsDefault  bool     `json:"is_default"`
+	City       string   `json:"city" validate:"required,max=100"`
+	Street     string   `json:"street" validate:"required,min=3,max=500"`
+	Unit       *string  `json:"unit" validate:"max=50"`
+	PostalCode string   `json:"postal_code" validate:"required" pattern:"^[0-9]{5,10}$"`
+	Latitude   *float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" validate:"min=-180,max=180"`
+}
+
+// AddressUpdateRequest changes the given fields of an address of the authorized customer.
+type AddressUpdateRequest struct {
+	AddressID  uint     `path:"address_id" json:"-"`
+	Label      *string  `json:"label" validate:"notblank,max=50"`
+	IsDefault  *bool    `json:"is_default"`
+	City       *string  `json:"city" validate:"notblank,max=100"`
+	Street     *string  `json:"street" validate:"notblank,min=3,max=500"`
+	Unit       *string  `json:"unit" validate:"max=50"`
+	PostalCode *string  `json:"postal_code" validate:"notblank" pattern:"^[0-9]{5,10}$"`
+	Latitude   *float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" validate:"min=-180,max=180"`
+}
+
+type AddressGetRequest struct {
+	AddressID uint `path:"address_id" json:"-"`
+}
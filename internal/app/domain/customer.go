@@ -1,10 +1,6 @@
 package domain
 
-import (
-	"logistic-app/internal/common/errors"
-	"net/http"
-	"time"
-)
+import "time"
 
 type Customer struct {
 	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -14,25 +10,68 @@ type Customer struct {
 	PostalCode  string    `json:"postal_code" gorm:"not null"`
 	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+
+	// PendingPhoneNumber is the phone number waiting to be verified, it is not stored with the customer.
+	PendingPhoneNumber *string `json:"pending_phone_number,omitempty" gorm:"-"`
 }
 
 type CustomerCreateRequest struct {
-	noPathReq
-	PhoneNumber string  `json:"phone_number" required:"true"`
-	Name        *string `json:"name"`
-	Address     string  `json:"address" required:"true"`
-	PostalCode  string  `json:"postal_code" required:"true"`
+	PhoneNumber string  `json:"phone_number" validate:"required,e164"`
+	Name        *string `json:"name" validate:"max=100"`
+	Address     string  `json:"address" validate:"required,min=3,max=500"`
+	PostalCode  string  `json:"postal_code" validate:"required" pattern:"^[0-9]{5,10}$"`
 }
 
-func (cr *CustomerCreateRequest) UnmarshalBody(request *http.Request) *errors.AppError {
-	return getBody(cr, request)
+type CustomerTokenRequest struct {
+	ID uint `json:"id" validate:"required"`
 }
 
-type CustomerTokenRequest struct {
-	noPathReq
-	ID uint `json:"id" required:"true"`
+type CustomerToken struct {
+	Token string `json:"token"`
+}
+
+// CustomerUpdateRequest changes the given fields of the profile, a new phone number
+// is only applied once it is verified.
+type CustomerUpdateRequest struct {
+	PhoneNumber *string `json:"phone_number" validate:"notblank,e164"`
+	Name        *string `json:"name" validate:"max=100"`
+	Address     *string `json:"address" validate:"notblank,min=3,max=500"`
+	PostalCode  *string `json:"postal_code" validate:"notblank" pattern:"^[0-9]{5,10}$"`
+}
+
+type PhoneVerifyRequest struct {
+	Code string `json:"code" validate:"required" pattern:"^[0-9]{6}$"`
+}
+
+// CustomerUpdate holds the fields to change, nil fields are left as they are.
+type CustomerUpdate struct {
+	PhoneNumber *string
+	Name        *string
+	Address     *string
+	PostalCode  *string
+}
+
+// CustomerAudit records the change of a field of a customer.
+type CustomerAudit struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID uint      `json:"customer_id" gorm:"not null;index"`
+	Field      string    `json:"field" gorm:"size:32;not null"`
+	OldValue   *string   `json:"old_value"`
+	NewValue   *string   `json:"new_value"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
+}
+
+// PhoneVerification is a pending change of the phone number of a customer, only the hash of the code is kept.
+type PhoneVerification struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID  uint      `json:"customer_id" gorm:"not null;uniqueIndex"`
+	PhoneNumber string    `json:"phone_number" gorm:"not null"`
+	CodeHash    string    `json:"-" gorm:"size:64;not null"`
+	Attempts    int       `json:"attempts" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null"`
 }
 
-func (cr *CustomerTokenRequest) UnmarshalBody(request *http.Request) *errors.AppError {
-	return getBody(cr, request)
+func (pv *PhoneVerification) Expired() bool {
+	return time.Now().After(pv.ExpiresAt)
 }
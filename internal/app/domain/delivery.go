@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"logistic-app/internal/common/configs"
+	"strings"
+	"time"
+)
+
+// DeliverySlot is a window of time the receiver asked the order to be delivered in.
+type DeliverySlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// OfferDeliverySlots returns the slots the receiver can reschedule the delivery to, from the day after now for
+// DELIVERY_SLOT_DAYS days, each day split into windows of DELIVERY_SLOT_HOURS hours within the delivery hours.
+func OfferDeliverySlots(now time.Time) []*DeliverySlot {
+	var slots []*DeliverySlot
+	for day := 1; day <= configs.DeliverySlotDays; day++ {
+		for hour := configs.DeliveryDayStart; hour+configs.DeliverySlotHours <= configs.DeliveryDayEnd; hour += configs.DeliverySlotHours {
+			start := time.Date(now.Year(), now.Month(), now.Day()+day, hour, 0, 0, 0, now.Location())
+			slots = append(slots, &DeliverySlot{Start: start, End: start.Add(time.Duration(configs.DeliverySlotHours) * time.Hour)})
+		}
+	}
+	return slots
+}
+
+// isOffered reports whether the slot is one of the slots offered at now.
+func (s *DeliverySlot) isOffered(now time.Time) bool {
+	for _, offered := range OfferDeliverySlots(now) {
+		if offered.Start.Equal(s.Start) && offered.End.Equal(s.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderListRequest lists the orders sent to the authorized customer, the status only keeps the orders having it.
+type OrderListRequest struct {
+	Pagination
+	Status *string `query:"status" validate:"oneof=PENDING PROVIDER_SEEN PICKED_UP IN_PROGRESS PARTIALLY_DELIVERED DELIVERED"`
+}
+
+// OrderDeliveryUpdateRequest changes the delivery of an order sent to the authorized customer, the fields left out
+// are kept. Rescheduling to a slot stops holding the order at the location of the provider, holding it clears its slot.
+type OrderDeliveryUpdateRequest struct {
+	OrderID        uint          `path:"order_id" json:"-"`
+	Slot           *DeliverySlot `json:"slot"`
+	HoldAtLocation *bool         `json:"hold_at_location"`
+	// Instructions are cleared when sent empty
+	Instructions *string `json:"instructions" validate:"max=500"`
+}
+
+// Apply changes the delivery of the order and returns the invalid fields of the request,
+// keyed by their json path. The slot must be one of the slots offered at now.
+func (r *OrderDeliveryUpdateRequest) Apply(order *Order, now time.Time) map[string]string {
+	switch {
+	case r.Slot == nil && r.HoldAtLocation == nil && r.Instructions == nil:
+		return map[string]string{"slot": "one of slot, hold_at_location and instructions is required"}
+	case r.Slot != nil && r.HoldAtLocation != nil && *r.HoldAtLocation:
+		return map[string]string{"hold_at_location": "cannot be true along with slot"}
+	case r.Slot != nil && !r.Slot.isOffered(now):
+		return map[string]string{"slot": "is not an offered delivery slot"}
+	}
+
+	if r.Slot != nil {
+		order.DeliverySlotStart, order.DeliverySlotEnd = &r.Slot.Start, &r.Slot.End
+		order.HoldAtLocation = false
+	}
+	if r.HoldAtLocation != nil {
+		order.HoldAtLocation = *r.HoldAtLocation
+		if order.HoldAtLocation {
+			order.DeliverySlotStart, order.DeliverySlotEnd = nil, nil
+		}
+	}
+	if r.Instructions != nil {
+		order.DeliveryInstructions = nil
+		if instructions := strings.TrimSpace(*r.Instructions); instructions != "" {
+			order.DeliveryInstructions = &instructions
+		}
+	}
+	return nil
+}
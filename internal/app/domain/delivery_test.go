@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOfferDeliverySlots(t *testing.T) {
+	now := time.Date(2025, 4, 21, 15, 30, 0, 0, time.UTC)
+	slots := OfferDeliverySlots(now)
+
+	assert.Len(t, slots, 15)
+	assert.Equal(t, time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC), slots[0].Start)
+	assert.Equal(t, time.Date(2025, 4, 22, 13, 0, 0, 0, time.UTC), slots[0].End)
+	assert.Equal(t, time.Date(2025, 4, 26, 21, 0, 0, 0, time.UTC), slots[14].End)
+}
+
+func TestOrderDeliveryUpdateRequest_Apply(t *testing.T) {
+	now := time.Date(2025, 4, 21, 15, 30, 0, 0, time.UTC)
+	slot := &DeliverySlot{Start: time.Date(2025, 4, 23, 13, 0, 0, 0, time.UTC), End: time.Date(2025, 4, 23, 17, 0, 0, 0, time.UTC)}
+	hold, release := true, false
+
+	t.Run("Reschedules To An Offered Slot", func(t *testing.T) {
+		order := &Order{HoldAtLocation: true}
+		assert.Empty(t, (&OrderDeliveryUpdateRequest{Slot: slot}).Apply(order, now))
+		assert.Equal(t, slot.Start, *order.DeliverySlotStart)
+		assert.Equal(t, slot.End, *order.DeliverySlotEnd)
+		assert.False(t, order.HoldAtLocation)
+	})
+
+	t.Run("Holding Clears The Slot", func(t *testing.T) {
+		order := &Order{DeliverySlotStart: &slot.Start, DeliverySlotEnd: &slot.End}
+		assert.Empty(t, (&OrderDeliveryUpdateRequest{HoldAtLocation: &hold}).Apply(order, now))
+		assert.True(t, order.HoldAtLocation)
+		assert.Nil(t, order.DeliverySlotStart)
+	})
+
+	t.Run("Instructions", func(t *testing.T) {
+		order := &Order{}
+		instructions := " ring twice "
+		assert.Empty(t, (&OrderDeliveryUpdateRequest{Instructions: &instructions}).Apply(order, now))
+		assert.Equal(t, "ring twice", *order.DeliveryInstructions)
+
+		empty := ""
+		assert.Empty(t, (&OrderDeliveryUpdateRequest{Instructions: &empty}).Apply(order, now))
+		assert.Nil(t, order.DeliveryInstructions)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		other := &DeliverySlot{Start: slot.Start.Add(time.Hour), End: slot.End.Add(time.Hour)}
+		assert.Contains(t, (&OrderDeliveryUpdateRequest{}).Apply(&Order{}, now), "slot")
+		assert.Contains(t, (&OrderDeliveryUpdateRequest{Slot: other}).Apply(&Order{}, now), "slot")
+		assert.Contains(t, (&OrderDeliveryUpdateRequest{Slot: slot, HoldAtLocation: &hold}).Apply(&Order{}, now), "hold_at_location")
+		assert.Empty(t, (&OrderDeliveryUpdateRequest{Slot: slot, HoldAtLocation: &release}).Apply(&Order{}, now))
+	})
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"logistic-app/internal/common/configs"
+	"math"
+	"slices"
+	"time"
+)
+
+// DeliveryEstimate is the expected delivery date of an order, between the earliest and the latest dates which hold
+// 80% of the deliveries it is estimated from. Its fields are null when there were no deliveries to estimate from.
+type DeliveryEstimate struct {
+	Date     *time.Time `json:"date" gorm:"type:date"`
+	Earliest *time.Time `json:"earliest" gorm:"type:date"`
+	Latest   *time.Time `json:"latest" gorm:"type:date"`
+	// Basis is the deliveries of the provider the estimate is made from, on the same route, to the same
+	// destination or any of them, and ByWeekday whether they were narrowed down to the same day of the week
+	Basis     *string `json:"basis" gorm:"size:20"`
+	ByWeekday bool    `json:"by_weekday" gorm:"not null;default:false"`
+	Samples   uint    `json:"samples" gorm:"not null;default:0"`
+}
+
+// DeliverySample is a delivered order of a provider, orders are estimated by how long the ones like them took.
+type DeliverySample struct {
+	PickupPostalCode  string
+	DropoffPostalCode string
+	CreatedAt         time.Time
+	PickedUpDate      time.Time
+	DeliveryDate      time.Time
+}
+
+type EstimateBasis struct {
+	Route       string
+	Destination string
+	Provider    string
+}
+
+func GetEstimateBasis() *EstimateBasis {
+	return &EstimateBasis{
+		Route:       "route",
+		Destination: "destination",
+		Provider:    "provider",
+	}
+}
+
+// Region is the first ESTIMATE_REGION_DIGITS digits of the postal code, orders between the same regions are
+// expected to take as long.
+func Region(postalCode string) string {
+	if len(postalCode) < configs.EstimateRegionDigits {
+		return postalCode
+	}
+	return postalCode[:configs.EstimateRegionDigits]
+}
+
+// EstimateDelivery estimates the delivery of the order from the deliveries of its provider. The deliveries on the
+// same route are used when there are at least ESTIMATE_MIN_SAMPLES of them, then the ones to the same destination,
+// then all of them, and they are narrowed down to the ones starting on the same day of the week when enough do.
+// Orders which are picked up are estimated by the time from the pickup to the delivery, the others by the time
+// from the creation. Delivered orders have no estimate, and late orders are expected today at the earliest.
+func EstimateDelivery(order *Order, samples []*DeliverySample, now time.Time) DeliveryEstimate {
+	if order.Status == GetOrderStatus().Delivered || len(samples) == 0 {
+		return DeliveryEstimate{}
+	}
+	from := date(order.CreatedAt)
+	if order.PickedUpDate != nil {
+		from = date(*order.PickedUpDate)
+	}
+	start := func(sample *DeliverySample) time.Time {
+		if order.PickedUpDate != nil {
+			return date(sample.PickedUpDate)
+		}
+		return date(sample.CreatedAt)
+	}
+
+	origin, destination := Region(order.PickupPostalCode), Region(order.DropoffPostalCode)
+	basis := GetEstimateBasis().Provider
+	for _, b := range []struct {
+		name  string
+		match func(*DeliverySample) bool
+	}{
+		{GetEstimateBasis().Route, func(s *DeliverySample) bool {
+			return Region(s.PickupPostalCode) == origin && Region(s.DropoffPostalCode) == destination
+		}},
+		{GetEstimateBasis().Destination, func(s *DeliverySample) bool { return Region(s.DropoffPostalCode) == destination }},
+	} {
+		if matched := filterSamples(samples, b.match); len(matched) >= configs.EstimateMinSamples {
+			samples, basis = matched, b.name
+			break
+		}
+	}
+	estimate := DeliveryEstimate{Basis: &basis}
+	weekday := filterSamples(samples, func(s *DeliverySample) bool { return start(s).Weekday() == from.Weekday() })
+	if len(weekday) >= configs.EstimateMinSamples {
+		samples, estimate.ByWeekday = weekday, true
+	}
+
+	days := make([]int, len(samples))
+	for i, sample := range samples {
+		days[i] = int(math.Round(date(sample.DeliveryDate).Sub(start(sample)).Hours() / 24))
+	}
+	slices.Sort(days)
+	at := func(percentile float64) *time.Time {
+		d := from.AddDate(0, 0, days[max(int(math.Ceil(percentile*float64(len(days))))-1, 0)])
+		return &d
+	}
+	estimate.Earliest, estimate.Date, estimate.Latest = at(0.1), at(0.5), at(0.9)
+	estimate.Samples = uint(len(samples))
+	return estimate.AsOf(now)
+}
+
+// AsOf returns the estimate as of the day of now. The estimate is only made again when the status of the order
+// changes, so the dates which passed while the order stalled are moved to today.
+func (e DeliveryEstimate) AsOf(now time.Time) DeliveryEstimate {
+	today := date(now)
+	clamp := func(d *time.Time) *time.Time {
+		if d != nil && d.Before(today) {
+			return &today
+		}
+		return d
+	}
+	e.Earliest, e.Date, e.Latest = clamp(e.Earliest), clamp(e.Date), clamp(e.Latest)
+	return e
+}
+
+func filterSamples(samples []*DeliverySample, match func(*DeliverySample) bool) []*DeliverySample {
+	var matched []*DeliverySample
+	for _, sample := range samples {
+		if match(sample) {
+			matched = append(matched, sample)
+		}
+	}
+	return matched
+}
+
+// date returns the day of the time as a date in UTC, like the dates read from the database.
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEstimateDelivery(t *testing.T) {
+	monday := time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)
+	sample := func(pickup, dropoff string, pickedUp time.Time, days int) *DeliverySample {
+		return &DeliverySample{PickupPostalCode: pickup, DropoffPostalCode: dropoff,
+			CreatedAt: pickedUp.AddDate(0, 0, -1), PickedUpDate: pickedUp, DeliveryDate: pickedUp.AddDate(0, 0, days)}
+	}
+	var samples []*DeliverySample
+	for i := range 5 {
+		// the route of the order takes longer than the other deliveries of the provider
+		samples = append(samples, sample("1234567890", "6372687", monday.AddDate(0, 0, -7*(i+1)), 3+i%2))
+		samples = append(samples, sample("5512345678", "4412345678", monday.AddDate(0, 0, -i-1), 1))
+	}
+	order := &Order{PickupPostalCode: "1298765432", DropoffPostalCode: "6399999", PickedUpDate: &monday, CreatedAt: monday}
+
+	t.Run("By The Route And The Weekday", func(t *testing.T) {
+		estimate := EstimateDelivery(order, samples, monday)
+		assert.Equal(t, GetEstimateBasis().Route, *estimate.Basis)
+		assert.True(t, estimate.ByWeekday)
+		assert.Equal(t, uint(5), estimate.Samples)
+		assert.Equal(t, monday.AddDate(0, 0, 3), *estimate.Earliest)
+		assert.Equal(t, monday.AddDate(0, 0, 3), *estimate.Date)
+		assert.Equal(t, monday.AddDate(0, 0, 4), *estimate.Latest)
+	})
+
+	t.Run("By The Provider Without Enough Deliveries On The Route", func(t *testing.T) {
+		tuesday := monday.AddDate(0, 0, 1)
+		other := &Order{PickupPostalCode: "9912345678", DropoffPostalCode: "8812345678", PickedUpDate: &tuesday, CreatedAt: monday}
+		estimate := EstimateDelivery(other, samples, monday)
+		assert.Equal(t, GetEstimateBasis().Provider, *estimate.Basis)
+		assert.False(t, estimate.ByWeekday)
+		assert.Equal(t, uint(10), estimate.Samples)
+		assert.Equal(t, tuesday.AddDate(0, 0, 1), *estimate.Earliest)
+		assert.Equal(t, tuesday.AddDate(0, 0, 4), *estimate.Latest)
+	})
+
+	t.Run("Today At The Earliest", func(t *testing.T) {
+		estimate := EstimateDelivery(order, samples, monday.AddDate(0, 0, 10))
+		assert.Equal(t, monday.AddDate(0, 0, 10), *estimate.Earliest)
+		assert.Equal(t, monday.AddDate(0, 0, 10), *estimate.Latest)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		assert.Nil(t, EstimateDelivery(order, nil, monday).Date)
+		assert.Nil(t, EstimateDelivery(&Order{Status: GetOrderStatus().Delivered}, samples, monday).Date)
+	})
+}
+
+func TestDeliveryEstimate_AsOf(t *testing.T) {
+	monday := time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)
+	earliest, date, latest := monday.AddDate(0, 0, 1), monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 4)
+	estimate := DeliveryEstimate{Earliest: &earliest, Date: &date, Latest: &latest}
+
+	t.Run("Unchanged Before The Dates", func(t *testing.T) {
+		assert.Equal(t, estimate, estimate.AsOf(monday.Add(15*time.Hour)))
+	})
+
+	t.Run("Passed Dates Moved To Today", func(t *testing.T) {
+		thursday := monday.AddDate(0, 0, 3)
+		stalled := estimate.AsOf(thursday.Add(15 * time.Hour))
+		assert.Equal(t, thursday, *stalled.Earliest)
+		assert.Equal(t, thursday, *stalled.Date)
+		assert.Equal(t, latest, *stalled.Latest)
+		assert.Equal(t, date, *estimate.Date)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		assert.Nil(t, DeliveryEstimate{}.AsOf(monday).Date)
+	})
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+type HealthStatus struct {
+	Up       string
+	Degraded string
+	Down     string
+}
+
+func GetHealthStatus() *HealthStatus {
+	return &HealthStatus{
+		Up:       "up",
+		Degraded: "degraded",
+		Down:     "down",
+	}
+}
+
+type HealthCheck struct {
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+type HealthReport struct {
+	Status    string                  `json:"status"`
+	Checks    map[string]*HealthCheck `json:"checks,omitempty"`
+	CheckedAt time.Time               `json:"checked_at"`
+}
+
+// NewHealthReport derives the overall status from the checks: down if any check is down,
+// degraded if any check is degraded and up otherwise.
+func NewHealthReport(checks map[string]*HealthCheck) *HealthReport {
+	status := GetHealthStatus().Up
+	for _, check := range checks {
+		if check.Status == GetHealthStatus().Down {
+			status = GetHealthStatus().Down
+			break
+		}
+		if check.Status == GetHealthStatus().Degraded {
+			status = GetHealthStatus().Degraded
+		}
+	}
+	return &HealthReport{
+		Status:    status,
+		Checks:    checks,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (hr *HealthReport) Ready() bool {
+	return hr.Status != GetHealthStatus().Down
+}
+
+type SchemaMigration struct {
+	Version   uint      `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
+
+type MigrationVersion struct {
+	Applied  uint `json:"applied"`
+	Expected uint `json:"expected"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord keeps the response of a request sent with an Idempotency-Key header.
+// A zero ResponseCode means the first request is still being processed.
+type IdempotencyRecord struct {
+	ID              uint              `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint              `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_user_key"`
+	IdempotencyKey  string            `json:"idempotency_key" gorm:"size:255;not null;uniqueIndex:idx_idempotency_user_key"`
+	RequestHash     string            `json:"request_hash" gorm:"size:64;not null"`
+	ResponseCode    int               `json:"response_code" gorm:"not null;default:0"`
+	ResponseHeaders map[string]string `json:"response_headers" gorm:"serializer:json"`
+	ResponseBody    []byte            `json:"response_body"`
+	CreatedAt       time.Time         `json:"created_at" gorm:"not null"`
+	ExpiresAt       time.Time         `json:"expires_at" gorm:"not null;index"`
+}
+
+func (ir *IdempotencyRecord) Completed() bool {
+	return ir.ResponseCode != 0
+}
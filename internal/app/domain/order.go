@@ -1,26 +1,143 @@
 package domain
 
 import (
-	"logistic-app/internal/common/errors"
-	"net/http"
+	"fmt"
+	"slices"
 	"time"
 )
 
 type Order struct {
-	ID               uint       `json:"id" gorm:"primaryKey;autoIncrement"`
-	ProviderID       uint       `json:"provider_id" gorm:"index;not null"`
-	Provider         *Provider  `json:"provider,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	SenderID         uint       `json:"sender_id" gorm:"index;not null"`
-	Sender           *Customer  `json:"sender,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	ReceiverID       uint       `json:"receiver_id" gorm:"index;not null"`
-	Receiver         *Customer  `json:"receiver,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Product          *string    `json:"product"`
-	Status           string     `json:"status" gorm:"size:15;not null;default:'PROVIDER_SEEN'"`
-	PickedUpDate     *time.Time `json:"picked_up_date" gorm:"type:date"`
-	DeliveryDate     *time.Time `json:"delivery_date" gorm:"type:date"`
-	NotifiedReceiver bool       `json:"notified_receiver" gorm:"not null;default:false"`
-	CreatedAt        time.Time  `json:"created_at" gorm:"not null;index"`
-	UpdatedAt        time.Time  `json:"updated_at" gorm:"not null"`
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TrackingCode string    `json:"tracking_code" gorm:"size:12;not null;uniqueIndex"`
+	ProviderID   uint      `json:"provider_id" gorm:"index;not null"`
+	Provider     *Provider `json:"provider,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SenderID     uint      `json:"sender_id" gorm:"index;not null"`
+	Sender       *Customer `json:"sender,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ReceiverID   uint      `json:"receiver_id" gorm:"index;not null"`
+	Receiver     *Customer `json:"receiver,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Product      *string   `json:"product"`
+	// total of the parcels in kilograms and centimeters, its weight is zero on orders created before parcels were taken
+	Parcel  Parcel              `json:"parcel" gorm:"embedded;embeddedPrefix:parcel_"`
+	Parcels []*OrderParcel      `json:"parcels,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	History []*OrderStatusEvent `json:"history,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// contacts and addresses of the sender and the receiver when the order was created,
+	// the saved addresses they were taken from are referenced until they are deleted
+	PickupContactName   *string  `json:"pickup_contact_name"`
+	PickupContactPhone  string   `json:"pickup_contact_phone" gorm:"not null;default:''"`
+	PickupAddressID     *uint    `json:"pickup_address_id" gorm:"index"`
+	PickupLocation      *Address `json:"-" gorm:"foreignKey:PickupAddressID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	PickupAddress       string   `json:"pickup_address" gorm:"not null;default:''"`
+	PickupPostalCode    string   `json:"pickup_postal_code" gorm:"not null;default:''"`
+	PickupLatitude      *float64 `json:"pickup_latitude"`
+	PickupLongitude     *float64 `json:"pickup_longitude"`
+	DropoffContactName  *string  `json:"dropoff_contact_name"`
+	DropoffContactPhone string   `json:"dropoff_contact_phone" gorm:"not null;default:''"`
+	DropoffAddressID    *uint    `json:"dropoff_address_id" gorm:"index"`
+	DropoffLocation     *Address `json:"-" gorm:"foreignKey:DropoffAddressID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	DropoffAddress      string   `json:"dropoff_address" gorm:"not null;default:''"`
+	DropoffPostalCode   string   `json:"dropoff_postal_code" gorm:"not null;default:''"`
+	DropoffLatitude     *float64 `json:"dropoff_latitude"`
+	DropoffLongitude    *float64 `json:"dropoff_longitude"`
+	// delivery asked by the receiver before the pickup, the order is delivered in the slot or held at the
+	// location of the provider for the receiver to collect
+	DeliverySlotStart    *time.Time `json:"delivery_slot_start"`
+	DeliverySlotEnd      *time.Time `json:"delivery_slot_end"`
+	HoldAtLocation       bool       `json:"hold_at_location" gorm:"not null;default:false"`
+	DeliveryInstructions *string    `json:"delivery_instructions" gorm:"size:500"`
+	// price of the quote the order was created from, the quote is referenced until it is deleted
+	QuoteID *uint  `json:"quote_id" gorm:"index"`
+	Quote   *Quote `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Price   *int64 `json:"price"`
+	// strategy and scores of the candidates which selected the provider, null when the sender chose it
+	SelectionStrategy *string          `json:"selection_strategy" gorm:"size:20"`
+	SelectionScores   []*ProviderScore `json:"selection_scores" gorm:"type:jsonb;serializer:json"`
+	Status            string           `json:"status" gorm:"size:20;not null;default:'PROVIDER_SEEN'"`
+	PickedUpDate      *time.Time       `json:"picked_up_date" gorm:"type:date"`
+	DeliveryDate      *time.Time       `json:"delivery_date" gorm:"type:date"`
+	// EstimatedDelivery is estimated when the order is created and again whenever its status changes
+	EstimatedDelivery DeliveryEstimate `json:"estimated_delivery" gorm:"embedded;embeddedPrefix:estimated_delivery_"`
+	// whether the shipment is booked with the provider, failed bookings are tried again by the periodic task
+	// until BOOKING_MAX_ATTEMPTS attempts failed
+	BookingStatus    string    `json:"booking_status" gorm:"size:10;not null;default:'PENDING';index"`
+	BookingAttempts  uint      `json:"booking_attempts" gorm:"not null;default:0"`
+	NotifiedReceiver bool      `json:"notified_receiver" gorm:"not null;default:false"`
+	CreatedAt        time.Time `json:"created_at" gorm:"not null;index"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// SetPickup keeps the contact and the address of the sender on the order, addresses without an ID are not saved ones.
+func (o *Order) SetPickup(contact *Customer, address *Address) {
+	o.PickupContactName, o.PickupContactPhone = contact.Name, contact.PhoneNumber
+	o.PickupAddressID = savedAddressID(address)
+	o.PickupAddress, o.PickupPostalCode = address.Line(), address.PostalCode
+	o.PickupLatitude, o.PickupLongitude = address.Latitude, address.Longitude
+}
+
+// SetDropoff keeps the contact and the address of the receiver on the order, addresses without an ID are not saved ones.
+func (o *Order) SetDropoff(contact *Customer, address *Address) {
+	o.DropoffContactName, o.DropoffContactPhone = contact.Name, contact.PhoneNumber
+	o.DropoffAddressID = savedAddressID(address)
+	o.DropoffAddress, o.DropoffPostalCode = address.Line(), address.PostalCode
+	o.DropoffLatitude, o.DropoffLongitude = address.Latitude, address.Longitude
+}
+
+// SetParcels sets the parcels of the order, numbered from 1, and their total. An order has a parcel
+// even when no parcel was given, so its status is tracked like the one of any other order.
+func (o *Order) SetParcels(parcels []Parcel) {
+	if len(parcels) == 0 {
+		parcels = []Parcel{o.Parcel}
+	}
+	o.Parcel = TotalParcel(parcels)
+	o.Parcels = make([]*OrderParcel, len(parcels))
+	for i, parcel := range parcels {
+		o.Parcels[i] = &OrderParcel{Number: uint(i + 1), Parcel: parcel, Status: o.Status}
+	}
+}
+
+// DeriveStatus sets the status and the dates of the order from its parcels. The order is delivered once all
+// of its parcels are and partially delivered once some are, otherwise it takes the status of its most
+// advanced parcel. It is picked up with its first parcel and delivered with its last one.
+func (o *Order) DeriveStatus() {
+	if len(o.Parcels) == 0 {
+		return
+	}
+	status, delivered := o.Parcels[0].Status, 0
+	o.PickedUpDate, o.DeliveryDate = nil, nil
+	for _, parcel := range o.Parcels {
+		if orderStatusRank(parcel.Status) > orderStatusRank(status) {
+			status = parcel.Status
+		}
+		if parcel.Status == GetOrderStatus().Delivered {
+			delivered++
+			if o.DeliveryDate == nil || (parcel.DeliveryDate != nil && parcel.DeliveryDate.After(*o.DeliveryDate)) {
+				o.DeliveryDate = parcel.DeliveryDate
+			}
+		}
+		if parcel.PickedUpDate != nil && (o.PickedUpDate == nil || parcel.PickedUpDate.Before(*o.PickedUpDate)) {
+			o.PickedUpDate = parcel.PickedUpDate
+		}
+	}
+	switch {
+	case delivered == len(o.Parcels):
+		o.Status = GetOrderStatus().Delivered
+	case delivered > 0:
+		o.Status, o.DeliveryDate = GetOrderStatus().PartiallyDelivered, nil
+	default:
+		o.Status = status
+	}
+}
+
+// DeliveryChangeable reports whether the receiver can still change the delivery, until the order is picked up.
+func (o *Order) DeliveryChangeable() bool {
+	return slices.Contains(GetUnpickedOrderStatus(), o.Status)
+}
+
+func savedAddressID(address *Address) *uint {
+	if address.ID == 0 {
+		return nil
+	}
+	id := address.ID
+	return &id
 }
 
 type OrderStatus struct {
@@ -29,15 +146,32 @@ type OrderStatus struct {
 	ProviderSeen string
 	PickedUp     string
 	Delivered    string
+	// PartiallyDelivered is only a status of orders, some of their parcels are delivered
+	PartiallyDelivered string
 }
 
 func GetOrderStatus() *OrderStatus {
 	return &OrderStatus{
-		Pending:      "PENDING",
-		InProgress:   "IN_PROGRESS",
-		ProviderSeen: "PROVIDER_SEEN",
-		PickedUp:     "PICKED_UP",
-		Delivered:    "DELIVERED",
+		Pending:            "PENDING",
+		InProgress:         "IN_PROGRESS",
+		ProviderSeen:       "PROVIDER_SEEN",
+		PickedUp:           "PICKED_UP",
+		Delivered:          "DELIVERED",
+		PartiallyDelivered: "PARTIALLY_DELIVERED",
+	}
+}
+
+type OrderBookingStatus struct {
+	Pending string
+	Booked  string
+	Failed  string
+}
+
+func GetOrderBookingStatus() *OrderBookingStatus {
+	return &OrderBookingStatus{
+		Pending: "PENDING",
+		Booked:  "BOOKED",
+		Failed:  "FAILED",
 	}
 }
 
@@ -46,6 +180,15 @@ func GetOngoingOrderStatus() []string {
 		GetOrderStatus().InProgress,
 		GetOrderStatus().ProviderSeen,
 		GetOrderStatus().PickedUp,
+		GetOrderStatus().PartiallyDelivered,
+	}
+}
+
+// GetUnpickedOrderStatus returns the statuses of orders which are not picked up yet.
+func GetUnpickedOrderStatus() []string {
+	return []string{
+		GetOrderStatus().Pending,
+		GetOrderStatus().ProviderSeen,
 	}
 }
 
@@ -84,22 +227,35 @@ type ProviderUrlResponse struct {
 	Data    []*OrderStatusInResponse `json:"data"`
 }
 
-type OrderCreateRequest struct {
-	noPathReq
-	ProviderID uint    `json:"provider_id" required:"true"`
-	ReceiverID uint    `json:"receiver_id" required:"true"`
-	Product    *string `json:"product_name"`
+// Validate checks the response of a provider holds the statuses the order task reads.
+func (r *ProviderUrlResponse) Validate() error {
+	if r == nil || len(r.Data) == 0 {
+		return fmt.Errorf("response has no statuses in data")
+	}
+	for i, status := range r.Data {
+		if status == nil || ConvertOrderStatus(status.StatusNumber) == "" {
+			return fmt.Errorf("data[%d] has an unknown status", i)
+		}
+	}
+	return nil
 }
 
-func (or *OrderCreateRequest) UnmarshalBody(request *http.Request) *errors.AppError {
-	return getBody(or, request)
+// OrderCreateRequest creates an order, the provider is selected by the strategy when it is left out.
+type OrderCreateRequest struct {
+	ProviderID uint    `json:"provider_id"`
+	Strategy   *string `json:"strategy" validate:"oneof=cheapest fastest on_time round_robin"`
+	ReceiverID uint    `json:"receiver_id" validate:"required"`
+	Product    *string `json:"product_name" validate:"max=255"`
+	// parcel of the shipment, or its parcels when it has more than one, the parcel of the quote is used when both are left out
+	Parcel  *Parcel   `json:"parcel"`
+	Parcels []*Parcel `json:"parcels" validate:"max=20"`
+	// saved addresses of the sender and the receiver, their default addresses are used when left out
+	PickupAddressID  *uint `json:"pickup_address_id"`
+	DropoffAddressID *uint `json:"dropoff_address_id"`
+	// quote of the sender holding an option of the provider, its price is kept on the order
+	QuoteID *uint `json:"quote_id"`
 }
 
 type OrderGetRequest struct {
-	noBodyReq
-	OrderID uint `json:"order_id"`
-}
-
-func (or *OrderGetRequest) UnmarshalPathValue(request *http.Request) *errors.AppError {
-	return getPathValues(or, request)
+	OrderID uint `path:"order_id" json:"-"`
 }
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// OrderParcel is a parcel of an order, tracked by the provider on its own with its tracking number.
+type OrderParcel struct {
+	ID      uint `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID uint `json:"order_id" gorm:"not null;index"`
+	// Number is the position of the parcel in the order, from 1
+	Number         uint   `json:"number" gorm:"not null"`
+	TrackingNumber string `json:"tracking_number" gorm:"size:12;not null;uniqueIndex"`
+	Parcel         `gorm:"embedded"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:'PROVIDER_SEEN'"`
+	PickedUpDate   *time.Time `json:"picked_up_date" gorm:"type:date"`
+	DeliveryDate   *time.Time `json:"delivery_date" gorm:"type:date"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+// trackingAlphabet leaves out the letters read as digits, I, L, O and U.
+const trackingAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewTrackingNumber returns a random tracking number of 12 characters.
+func NewTrackingNumber() (string, error) {
+	b := make([]byte, 12)
+	if _, e := rand.Read(b); e != nil {
+		return "", e
+	}
+	for i := range b {
+		b[i] = trackingAlphabet[int(b[i])%len(trackingAlphabet)]
+	}
+	return string(b), nil
+}
+
+// orderStatusRank orders the statuses of a parcel by its progress.
+func orderStatusRank(status string) int {
+	switch status {
+	case GetOrderStatus().Pending:
+		return 0
+	case GetOrderStatus().ProviderSeen:
+		return 1
+	case GetOrderStatus().PickedUp:
+		return 2
+	case GetOrderStatus().InProgress:
+		return 3
+	case GetOrderStatus().Delivered:
+		return 4
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTrackingNumber(t *testing.T) {
+	first, e := NewTrackingNumber()
+	assert.NoError(t, e)
+	second, e := NewTrackingNumber()
+	assert.NoError(t, e)
+
+	assert.Len(t, first, 12)
+	assert.NotEqual(t, first, second)
+	for _, c := range first {
+		assert.True(t, strings.ContainsRune(trackingAlphabet, c))
+	}
+}
+
+func TestOrder_SetParcels(t *testing.T) {
+	t.Run("One Parcel Without Parcels", func(t *testing.T) {
+		order := &Order{}
+		order.SetParcels(nil)
+		assert.Len(t, order.Parcels, 1)
+		assert.Equal(t, uint(1), order.Parcels[0].Number)
+	})
+
+	t.Run("Numbers The Parcels And Keeps Their Total", func(t *testing.T) {
+		order := &Order{}
+		order.SetParcels([]Parcel{{Weight: 1, ChargeableWeight: 1, Pieces: 1}, {Weight: 2, ChargeableWeight: 2, Pieces: 1}})
+		assert.Len(t, order.Parcels, 2)
+		assert.Equal(t, uint(2), order.Parcels[1].Number)
+		assert.Equal(t, 3.0, order.Parcel.Weight)
+		assert.Equal(t, uint(2), order.Parcel.Pieces)
+	})
+}
+
+func TestOrder_DeriveStatus(t *testing.T) {
+	monday := time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)
+	tuesday, wednesday := monday.AddDate(0, 0, 1), monday.AddDate(0, 0, 2)
+	status := GetOrderStatus()
+
+	t.Run("Most Advanced Parcel Before Any Delivery", func(t *testing.T) {
+		order := &Order{Parcels: []*OrderParcel{
+			{Status: status.ProviderSeen},
+			{Status: status.InProgress, PickedUpDate: &tuesday},
+			{Status: status.PickedUp, PickedUpDate: &monday},
+		}}
+		order.DeriveStatus()
+		assert.Equal(t, status.InProgress, order.Status)
+		assert.Equal(t, monday, *order.PickedUpDate)
+		assert.Nil(t, order.DeliveryDate)
+	})
+
+	t.Run("Partially Delivered", func(t *testing.T) {
+		order := &Order{Parcels: []*OrderParcel{
+			{Status: status.Delivered, PickedUpDate: &monday, DeliveryDate: &tuesday},
+			{Status: status.PickedUp, PickedUpDate: &monday},
+		}}
+		order.DeriveStatus()
+		assert.Equal(t, status.PartiallyDelivered, order.Status)
+		assert.Nil(t, order.DeliveryDate)
+	})
+
+	t.Run("Delivered With The Last Parcel", func(t *testing.T) {
+		order := &Order{Parcels: []*OrderParcel{
+			{Status: status.Delivered, PickedUpDate: &monday, DeliveryDate: &wednesday},
+			{Status: status.Delivered, PickedUpDate: &monday, DeliveryDate: &tuesday},
+		}}
+		order.DeriveStatus()
+		assert.Equal(t, status.Delivered, order.Status)
+		assert.Equal(t, wednesday, *order.DeliveryDate)
+	})
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// VolumetricDivisor converts the volume of a parcel in cubic centimeters to its volumetric weight in kilograms.
+const VolumetricDivisor = 5000
+
+const (
+	maxParcelWeight    = 1000
+	maxParcelDimension = 500
+)
+
+// Parcel is the package of a shipment. It is sent in grams or kilograms and centimeters or inches,
+// and kept in kilograms and centimeters once normalized.
+type Parcel struct {
+	Weight        float64 `json:"weight" gorm:"not null;default:0" validate:"required,min=0.001"`
+	WeightUnit    string  `json:"weight_unit" gorm:"size:2;not null;default:'kg'" validate:"oneof=g kg"`
+	Length        float64 `json:"length" gorm:"not null;default:0" validate:"min=0"`
+	Width         float64 `json:"width" gorm:"not null;default:0" validate:"min=0"`
+	Height        float64 `json:"height" gorm:"not null;default:0" validate:"min=0"`
+	DimensionUnit string  `json:"dimension_unit" gorm:"size:2;not null;default:'cm'" validate:"oneof=cm in"`
+	// DeclaredValue is the value of the contents in rials, carriers insure the parcel up to it
+	DeclaredValue int64 `json:"declared_value" gorm:"not null;default:0" validate:"min=0"`
+	Pieces        uint  `json:"pieces" gorm:"not null;default:1" validate:"max=100"`
+	Fragile       bool  `json:"fragile" gorm:"not null;default:false"`
+	Hazardous     bool  `json:"hazardous" gorm:"not null;default:false"`
+	// ChargeableWeight is the larger of the weight and the volumetric weight, it is set when the parcel is normalized
+	ChargeableWeight float64 `json:"chargeable_weight" gorm:"not null;default:0"`
+}
+
+// Normalize converts the parcel to kilograms and centimeters, sets its chargeable weight and returns the
+// violations of the limits of carriers, keyed by the json path of the field under the path of the parcel.
+func (p *Parcel) Normalize(path string) map[string]string {
+	if p.WeightUnit == "g" {
+		p.Weight /= 1000
+	}
+	if p.DimensionUnit == "in" {
+		p.Length, p.Width, p.Height = p.Length*2.54, p.Width*2.54, p.Height*2.54
+	}
+	p.WeightUnit, p.DimensionUnit = "kg", "cm"
+	if p.Pieces == 0 {
+		p.Pieces = 1
+	}
+	p.ChargeableWeight = math.Ceil(math.Max(p.Weight, p.VolumetricWeight())*1000) / 1000
+
+	invalid := make(map[string]string)
+	if p.Weight > maxParcelWeight {
+		invalid[path+".weight"] = fmt.Sprintf("must be at most %d kg", maxParcelWeight)
+	}
+	given := 0
+	for name, dimension := range map[string]float64{"length": p.Length, "width": p.Width, "height": p.Height} {
+		if dimension > maxParcelDimension {
+			invalid[path+"."+name] = fmt.Sprintf("must be at most %d cm", maxParcelDimension)
+		}
+		if dimension > 0 {
+			given++
+		}
+	}
+	if given != 0 && given != 3 {
+		invalid[path+".length"] = "length, width and height must be given together"
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	return invalid
+}
+
+// TotalParcel sums the weights, the values and the pieces of the parcels of a shipment, and flags the total
+// as fragile or hazardous when any parcel is. Dimensions are only kept for a single parcel.
+func TotalParcel(parcels []Parcel) Parcel {
+	if len(parcels) == 1 {
+		return parcels[0]
+	}
+	total := Parcel{WeightUnit: "kg", DimensionUnit: "cm"}
+	for _, parcel := range parcels {
+		total.Weight += parcel.Weight
+		total.ChargeableWeight += parcel.ChargeableWeight
+		total.DeclaredValue += parcel.DeclaredValue
+		total.Pieces += parcel.Pieces
+		total.Fragile = total.Fragile || parcel.Fragile
+		total.Hazardous = total.Hazardous || parcel.Hazardous
+	}
+	total.Weight = math.Round(total.Weight*1000) / 1000
+	total.ChargeableWeight = math.Round(total.ChargeableWeight*1000) / 1000
+	return total
+}
+
+// VolumetricWeight returns the weight carriers charge for the space the parcel takes, in kilograms.
+func (p *Parcel) VolumetricWeight() float64 {
+	return p.Length * p.Width * p.Height / VolumetricDivisor
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParcel_Normalize(t *testing.T) {
+	t.Run("Weight Of A Dense Parcel", func(t *testing.T) {
+		parcel := &Parcel{Weight: 2.5, Length: 10, Width: 10, Height: 10}
+		assert.Empty(t, parcel.Normalize("parcel"))
+		assert.Equal(t, 2.5, parcel.ChargeableWeight)
+		assert.Equal(t, uint(1), parcel.Pieces)
+	})
+
+	t.Run("Volumetric Weight Of A Light Parcel", func(t *testing.T) {
+		parcel := &Parcel{Weight: 1, Length: 50, Width: 40, Height: 30}
+		assert.Empty(t, parcel.Normalize("parcel"))
+		assert.Equal(t, 12.0, parcel.ChargeableWeight)
+	})
+
+	t.Run("Converts Grams And Inches", func(t *testing.T) {
+		parcel := &Parcel{Weight: 1500, WeightUnit: "g", Length: 10, Width: 10, Height: 10, DimensionUnit: "in"}
+		assert.Empty(t, parcel.Normalize("parcel"))
+		assert.Equal(t, 1.5, parcel.Weight)
+		assert.Equal(t, "kg", parcel.WeightUnit)
+		assert.InDelta(t, 25.4, parcel.Length, 1e-9)
+		assert.Equal(t, "cm", parcel.DimensionUnit)
+		assert.Equal(t, 3.278, parcel.ChargeableWeight)
+	})
+
+	t.Run("Limits Of Carriers", func(t *testing.T) {
+		parcel := &Parcel{Weight: 2000, Length: 600, Width: 10}
+		invalid := parcel.Normalize("parcel")
+		assert.Equal(t, "must be at most 1000 kg", invalid["parcel.weight"])
+		assert.Equal(t, "length, width and height must be given together", invalid["parcel.length"])
+	})
+}
+
+func TestTotalParcel(t *testing.T) {
+	first := Parcel{Weight: 1.2, ChargeableWeight: 2.4, DeclaredValue: 1000, Pieces: 1, Length: 10, Width: 10, Height: 10}
+	second := Parcel{Weight: 0.1, ChargeableWeight: 0.1, DeclaredValue: 500, Pieces: 2, Fragile: true}
+
+	assert.Equal(t, first, TotalParcel([]Parcel{first}))
+
+	total := TotalParcel([]Parcel{first, second})
+	assert.Equal(t, 1.3, total.Weight)
+	assert.Equal(t, 2.5, total.ChargeableWeight)
+	assert.Equal(t, int64(1500), total.DeclaredValue)
+	assert.Equal(t, uint(3), total.Pieces)
+	assert.True(t, total.Fragile)
+	assert.False(t, total.Hazardous)
+	assert.Zero(t, total.Length)
+}
@@ -1,27 +1,76 @@
 package domain
 
-import (
-	"logistic-app/internal/common/errors"
-	"net/http"
-	"time"
-)
+import "time"
 
 type Provider struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string    `json:"name" gorm:"not null;unique"`
-	Url       string    `json:"url" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"not null"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+	ID   uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"not null;unique"`
+	Url  string `json:"url" gorm:"not null"`
+	// QuoteUrl prices the parcels of providers without tariffs
+	QuoteUrl *string `json:"quote_url"`
+	// SelectionWeight is the share of the orders round robin selection sends to the provider
+	SelectionWeight uint `json:"selection_weight" gorm:"not null;default:1"`
+	// Active providers take new orders, the ongoing orders of inactive providers are still tracked
+	Active bool `json:"active" gorm:"not null;default:true"`
+	// providers take orders once their url responded as expected
+	VerificationStatus    string     `json:"verification_status" gorm:"size:20;not null;default:'pending_verification'"`
+	VerificationDetail    *string    `json:"verification_detail"`
+	VerificationCheckedAt *time.Time `json:"verification_checked_at"`
+	CreatedAt             time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt             time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+type ProviderVerificationStatus struct {
+	Pending  string
+	Verified string
+}
+
+func GetProviderVerificationStatus() *ProviderVerificationStatus {
+	return &ProviderVerificationStatus{
+		Pending:  "pending_verification",
+		Verified: "verified",
+	}
+}
+
+// TakesOrders reports whether new orders can be sent to the provider.
+func (p *Provider) TakesOrders() bool {
+	return p.Active && p.VerificationStatus == GetProviderVerificationStatus().Verified
 }
 
 type ProviderCreateRequest struct {
-	noPathReq
-	Name string `json:"name" required:"true"`
-	Url  string `json:"url" required:"true"`
+	Name     string  `json:"name" validate:"required,min=2,max=100"`
+	Url      string  `json:"url" validate:"required,url"`
+	QuoteUrl *string `json:"quote_url" validate:"notblank,url"`
+}
+
+type ProviderGetRequest struct {
+	ProviderID uint `path:"provider_id" json:"-"`
+}
+
+// ProviderUpdateRequest changes the given fields of a provider, deactivating it blocks new orders.
+// A null quote url clears it, so the provider is priced by its tariffs.
+type ProviderUpdateRequest struct {
+	ProviderID uint             `path:"provider_id" json:"-"`
+	Name       *string          `json:"name" validate:"notblank,min=2,max=100"`
+	Url        *string          `json:"url" validate:"notblank,url"`
+	Active     *bool            `json:"active"`
+	QuoteUrl   Nullable[string] `json:"quote_url" validate:"notblank,url"`
+	// SelectionWeight is the share of the orders round robin selection sends to the provider
+	SelectionWeight *uint `json:"selection_weight" validate:"notblank,min=1,max=100"`
+}
+
+// ProviderListRequest lists the providers, the given postal codes only keep the providers serving them.
+// Unlike the other lists the limit is opt-in, all the providers are listed when it is left out.
+type ProviderListRequest struct {
+	Limit  uint64  `query:"limit" validate:"max=100"`
+	Offset uint64  `query:"offset" default:"0"`
+	From   *string `query:"from" pattern:"^[0-9]{5,10}$"`
+	To     *string `query:"to" pattern:"^[0-9]{5,10}$"`
 }
 
-func (pr *ProviderCreateRequest) UnmarshalBody(request *http.Request) *errors.AppError {
-	return getBody(pr, request)
+// Pagination returns the limit and the offset of the request, a zero limit is no limit.
+func (r *ProviderListRequest) Pagination() Pagination {
+	return Pagination{Limit: r.Limit, Offset: r.Offset}
 }
 
 type ProviderByDeliveryTime struct {
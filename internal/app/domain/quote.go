@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Tariff is a price of a provider for the parcels weighing up to MaxWeight kilograms.
+type Tariff struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProviderID   uint      `json:"provider_id" gorm:"not null;uniqueIndex:idx_tariffs_weight"`
+	Provider     *Provider `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MaxWeight    float64   `json:"max_weight" gorm:"not null;uniqueIndex:idx_tariffs_weight"`
+	Price        int64     `json:"price" gorm:"not null"`
+	DeliveryDays uint      `json:"delivery_days" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"not null"`
+}
+
+// TariffFor returns the cheapest tariff for the weight, or nil when the weight is above every tariff.
+func TariffFor(tariffs []*Tariff, weight float64) *Tariff {
+	var found *Tariff
+	for _, tariff := range tariffs {
+		if tariff.MaxWeight >= weight && (found == nil || tariff.Price < found.Price) {
+			found = tariff
+		}
+	}
+	return found
+}
+
+type TariffInput struct {
+	MaxWeight    float64 `json:"max_weight" validate:"required,min=0.001,max=1000"`
+	Price        int64   `json:"price" validate:"required,min=0"`
+	DeliveryDays uint    `json:"delivery_days" validate:"required,max=60"`
+}
+
+// TariffsUpdateRequest replaces the tariffs of a provider, providers without tariffs are quoted through their quote url.
+type TariffsUpdateRequest struct {
+	ProviderID uint           `path:"provider_id" json:"-"`
+	Tariffs    []*TariffInput `json:"tariffs" validate:"max=100"`
+}
+
+// Quote holds the prices of the providers serving the postal codes for the parcel, ranked from the best option.
+// Orders may reference it until it expires.
+type Quote struct {
+	ID                uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID        uint           `json:"customer_id" gorm:"not null;index"`
+	Customer          *Customer      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PickupPostalCode  string         `json:"pickup_postal_code" gorm:"size:10;not null"`
+	DropoffPostalCode string         `json:"dropoff_postal_code" gorm:"size:10;not null"`
+	Parcel            Parcel         `json:"parcel" gorm:"embedded;embeddedPrefix:parcel_"`
+	Options           []*QuoteOption `json:"options" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ExpiresAt         time.Time      `json:"expires_at" gorm:"not null"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"not null"`
+}
+
+// Expired reports whether orders can no longer reference the quote.
+func (q *Quote) Expired() bool {
+	return time.Now().After(q.ExpiresAt)
+}
+
+// Option returns the option of the provider, or nil when the provider was not quoted.
+func (q *Quote) Option(providerID uint) *QuoteOption {
+	for _, option := range q.Options {
+		if option.ProviderID == providerID {
+			return option
+		}
+	}
+	return nil
+}
+
+// QuoteOption is the price and the delivery time of a provider, along with the mean delivery time of its
+// recent orders when there are any.
+type QuoteOption struct {
+	ID                     uint     `json:"-" gorm:"primaryKey;autoIncrement"`
+	QuoteID                uint     `json:"-" gorm:"not null;index"`
+	Rank                   int      `json:"rank" gorm:"not null"`
+	ProviderID             uint     `json:"provider_id" gorm:"not null"`
+	ProviderName           string   `json:"provider_name" gorm:"not null"`
+	Price                  int64    `json:"price" gorm:"not null"`
+	DeliveryDays           float32  `json:"delivery_days" gorm:"not null"`
+	MeanDeliveryTimeInDays *float32 `json:"mean_delivery_time_in_days"`
+	// Source is tariff or provider, the provider quoted the price itself
+	Source string `json:"source" gorm:"size:10;not null"`
+}
+
+type QuoteSource struct {
+	Tariff   string
+	Provider string
+}
+
+func GetQuoteSource() *QuoteSource {
+	return &QuoteSource{
+		Tariff:   "tariff",
+		Provider: "provider",
+	}
+}
+
+// ExpectedDays returns the mean delivery time of the provider when it is known, or the quoted delivery time.
+func (o *QuoteOption) ExpectedDays() float32 {
+	if o.MeanDeliveryTimeInDays != nil {
+		return *o.MeanDeliveryTimeInDays
+	}
+	return o.DeliveryDays
+}
+
+// RankQuoteOptions orders the options by price, then by their expected delivery time, and numbers them from 1.
+func RankQuoteOptions(options []*QuoteOption) {
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Price != options[j].Price {
+			return options[i].Price < options[j].Price
+		}
+		return options[i].ExpectedDays() < options[j].ExpectedDays()
+	})
+	for i, option := range options {
+		option.Rank = i + 1
+	}
+}
+
+type QuoteCreateRequest struct {
+	PickupPostalCode  string `json:"pickup_postal_code" validate:"required" pattern:"^[0-9]{5,10}$"`
+	DropoffPostalCode string `json:"dropoff_postal_code" validate:"required" pattern:"^[0-9]{5,10}$"`
+	Parcel            Parcel `json:"parcel"`
+}
+
+// ProviderQuoteRequest is sent to the quote url of a provider, which answers with a ProviderQuote.
+type ProviderQuoteRequest struct {
+	PickupPostalCode  string  `json:"pickup_postal_code"`
+	DropoffPostalCode string  `json:"dropoff_postal_code"`
+	Parcel            *Parcel `json:"parcel"`
+	ChargeableWeight  float64 `json:"chargeable_weight"`
+}
+
+type ProviderQuote struct {
+	Price        int64   `json:"price"`
+	DeliveryDays float32 `json:"delivery_days"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTariffFor(t *testing.T) {
+	tariffs := []*Tariff{
+		{MaxWeight: 1, Price: 50_000, DeliveryDays: 2},
+		{MaxWeight: 5, Price: 90_000, DeliveryDays: 3},
+		{MaxWeight: 10, Price: 80_000, DeliveryDays: 5},
+	}
+
+	assert.Equal(t, int64(50_000), TariffFor(tariffs, 0.5).Price)
+	assert.Equal(t, int64(80_000), TariffFor(tariffs, 3).Price)
+	assert.Nil(t, TariffFor(tariffs, 12))
+}
+
+func TestRankQuoteOptions(t *testing.T) {
+	meanTime := float32(1.5)
+	options := []*QuoteOption{
+		{ProviderID: 1, Price: 90_000, DeliveryDays: 1},
+		{ProviderID: 2, Price: 70_000, DeliveryDays: 3},
+		{ProviderID: 3, Price: 70_000, DeliveryDays: 3, MeanDeliveryTimeInDays: &meanTime},
+	}
+
+	RankQuoteOptions(options)
+	assert.Equal(t, uint(3), options[0].ProviderID)
+	assert.Equal(t, uint(2), options[1].ProviderID)
+	assert.Equal(t, uint(1), options[2].ProviderID)
+	assert.Equal(t, 3, options[2].Rank)
+}
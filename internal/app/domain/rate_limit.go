@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// RateLimit allows bursts of Requests and refills the whole bucket over Period.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RefillRate returns the number of tokens added to the bucket per second.
+func (rl *RateLimit) RefillRate() float64 {
+	return float64(rl.Requests) / rl.Period.Seconds()
+}
+
+// Result builds the outcome of taking a token, given the tokens left in the bucket.
+func (rl *RateLimit) Result(allowed bool, tokens float64) *RateLimitResult {
+	rate := rl.RefillRate()
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     rl.Requests,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(rl.Requests) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey"`
+	Tokens    float64   `gorm:"type:double precision;not null"`
+	Allowed   bool      `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index"`
+}
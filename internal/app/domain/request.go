@@ -2,106 +2,369 @@ package domain
 
 import (
 	"encoding/json"
+	stdErrors "errors"
 	"fmt"
 	"io"
+	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
+	"mime"
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
-type Request interface {
-	UnmarshalBody(request *http.Request) *errors.AppError
-	UnmarshalPathValue(request *http.Request) *errors.AppError
+// Request types are bound from these sources:
+//
+//	path:"order_id"            a path value of the route
+//	query:"status" default:"x" a query parameter, lists are repeated or comma separated parameters
+//	body:"text/csv"            the raw body of the media type, for types not reading a JSON body
+//	json:"name"                any other field is read from the JSON body
+//
+// The body is only read for POST, PUT and PATCH requests of types having body fields.
+
+type Pagination struct {
+	Limit  uint64 `query:"limit" default:"10" validate:"max=100"`
+	Offset uint64 `query:"offset" default:"0"`
+}
+
+type PageInfo struct {
+	Limit  uint64 `json:"limit"`
+	Offset uint64 `json:"offset"`
+	Total  int64  `json:"total"`
+}
+
+// Paged is implemented by the results of list requests, their items are returned as the
+// data of the response and their page info as the pagination of its meta.
+type Paged interface {
+	Data() any
+	PageInfo() *PageInfo
 }
 
-func getLimitNOffset(r *http.Request) (uint64, uint64) {
-	return getCustomLimitNOffset(r, "limit", "offset", 10, 0)
+type Page[T any] struct {
+	Items []T
+	Info  PageInfo
 }
 
-func getCustomLimitNOffset(r *http.Request, limitKey, offsetKey string, defLimit, defOffset uint64) (uint64, uint64) {
-	limitStr := r.URL.Query().Get(limitKey)
-	offsetStr := r.URL.Query().Get(offsetKey)
-	limit, errConv := strconv.ParseUint(limitStr, 10, 64)
-	if errConv != nil {
-		limit = defLimit
+func NewPage[T any](items []T, pagination Pagination, total int64) *Page[T] {
+	if items == nil {
+		items = []T{}
 	}
-	offset, errConv := strconv.ParseUint(offsetStr, 10, 64)
-	if errConv != nil {
-		offset = defOffset
+	return &Page[T]{
+		Items: items,
+		Info:  PageInfo{Limit: pagination.Limit, Offset: pagination.Offset, Total: total},
 	}
-	return limit, offset
 }
 
-func getPathValues(r any, request *http.Request) *errors.AppError {
-	instType := reflect.TypeOf(r).Elem()
-	v := reflect.ValueOf(r).Elem()
+func (p *Page[T]) Data() any {
+	return p.Items
+}
+
+func (p *Page[T]) PageInfo() *PageInfo {
+	return &p.Info
+}
+
+// Bind populates the request type from the request and validates it.
+func Bind(r any, request *http.Request) *errors.AppError {
+	if hasBody(request.Method) {
+		var err *errors.AppError
+		if mediaType := RawBodyMediaType(reflect.TypeOf(r).Elem()); mediaType != "" {
+			err = getRawBody(r, request, mediaType)
+		} else if hasBodyFields(reflect.TypeOf(r).Elem()) {
+			err = getBody(r, request)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := getPathValues(r, request); err != nil {
+		return err
+	}
+	if err := getQueryValues(r, request); err != nil {
+		return err
+	}
 
-	for i := 0; i < instType.NumField(); i++ {
-		field := instType.Field(i)
-		jsonTag := field.Tag.Get("json")
+	if violations := validate(r); len(violations) > 0 {
+		return errors.ValidationError(violations)
+	}
+	return nil
+}
+
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func hasBodyFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if hasBodyFields(field.Type) {
+				return true
+			}
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("path") != "" || field.Tag.Get("query") != "" || field.Tag.Get("json") == "-" {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// RawBodyMediaType returns the media type of the field of the type bound from the raw body, if any.
+func RawBodyMediaType(t reflect.Type) string {
+	mediaType := ""
+	eachTaggedField(reflect.New(t).Elem(), "body", func(field reflect.StructField, value reflect.Value, name string) {
+		mediaType = name
+	})
+	return mediaType
+}
+
+// eachTaggedField calls f for the fields having the tag, including the ones of embedded structs.
+func eachTaggedField(v reflect.Value, tag string, f func(field reflect.StructField, value reflect.Value, name string)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			eachTaggedField(v.Field(i), tag, f)
+			continue
+		}
+		if name := field.Tag.Get(tag); name != "" && v.Field(i).CanSet() {
+			f(field, v.Field(i), name)
+		}
+	}
+}
+
+func getPathValues(r any, request *http.Request) *errors.AppError {
+	fields := make(map[string]string)
+	eachTaggedField(reflect.ValueOf(r).Elem(), "path", func(field reflect.StructField, value reflect.Value, name string) {
+		if e := setValue(value, []string{request.PathValue(name)}); e != nil {
+			fields[name] = e.Error()
+		}
+	})
+	if len(fields) > 0 {
+		return errors.InvalidRequest("invalid path value", fields)
+	}
+	return nil
+}
 
-		value := request.PathValue(jsonTag)
-		if fieldVal := v.FieldByName(field.Name); fieldVal.IsValid() && fieldVal.CanSet() {
-			if fieldVal.Kind() == reflect.String {
-				fieldVal.SetString(value)
+func getQueryValues(r any, request *http.Request) *errors.AppError {
+	query := request.URL.Query()
+	fields := make(map[string]string)
+	eachTaggedField(reflect.ValueOf(r).Elem(), "query", func(field reflect.StructField, value reflect.Value, name string) {
+		values, ok := query[name]
+		if !ok || len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			def, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				return
 			}
-			if fieldVal.Kind() == reflect.Uint {
-				n, e := strconv.ParseUint(value, 10, 64)
-				if e != nil {
-					return errors.InternalServerError(e)
+			values = []string{def}
+		}
+		if e := setValue(value, values); e != nil {
+			fields[name] = e.Error()
+		}
+	})
+	if len(fields) > 0 {
+		return errors.InvalidRequest("invalid query parameter", fields)
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// setValue parses the raw values into the field, pointers are allocated and
+// slices take every value, split by commas as well.
+func setValue(v reflect.Value, raw []string) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if e := setValue(elem.Elem(), raw); e != nil {
+			return e
+		}
+		v.Set(elem)
+		return nil
+	case v.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), 0, len(raw))
+		for _, r := range raw {
+			for _, part := range strings.Split(r, ",") {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if e := setValue(elem, []string{strings.TrimSpace(part)}); e != nil {
+					return e
 				}
-				fieldVal.SetUint(n)
+				slice = reflect.Append(slice, elem)
 			}
 		}
+		v.Set(slice)
+		return nil
+	}
+
+	value := raw[len(raw)-1]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, e := strconv.ParseBool(value)
+		if e != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, e := strconv.ParseInt(value, 10, v.Type().Bits())
+		if e != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, e := strconv.ParseUint(value, 10, v.Type().Bits())
+		if e != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, e := strconv.ParseFloat(value, v.Type().Bits())
+		if e != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(n)
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		t, e := time.Parse(time.RFC3339, value)
+		if e != nil {
+			t, e = time.Parse(time.DateOnly, value)
+		}
+		if e != nil {
+			return fmt.Errorf("must be a date like 2006-01-02 or a time like 2006-01-02T15:04:05Z")
+		}
+		v.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
 	}
 	return nil
 }
 
 func getBody(r any, request *http.Request) *errors.AppError {
-	b, err := io.ReadAll(request.Body)
-	if err != nil {
-		return errors.InternalServerError(err)
+	if err := checkContentType(request); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, request.Body, configs.MaxBodySize))
+	if configs.RejectUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decodeError(decoder.Decode(r)); err != nil {
+		return err
 	}
-	if len(b) == 0 {
-		errMsg := "body is missing"
-		return errors.BadRequest(errMsg)
+	if decoder.More() {
+		return errors.BadRequest(fmt.Sprintf("body must contain a single JSON object, found more data at position %d", decoder.InputOffset()))
 	}
+	return nil
+}
 
-	if err = json.Unmarshal(b, &r); err != nil {
-		return errors.InternalServerError(err)
+// getRawBody reads the body of the media type into the string or byte slice field tagged with it.
+func getRawBody(r any, request *http.Request, mediaType string) *errors.AppError {
+	contentType := request.Header.Get("Content-Type")
+	if parsed, _, e := mime.ParseMediaType(contentType); e != nil || parsed != mediaType {
+		return errors.UnsupportedMediaType(contentType, mediaType)
 	}
 
-	instType := reflect.TypeOf(r).Elem()
-	v := reflect.ValueOf(r).Elem()
+	body, e := io.ReadAll(http.MaxBytesReader(nil, request.Body, configs.MaxBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if stdErrors.As(e, &maxBytesErr) {
+		return errors.RequestEntityTooLarge(maxBytesErr.Limit)
+	} else if e != nil {
+		return errors.BadRequest("body could not be read")
+	}
 
-	for i := 0; i < instType.NumField(); i++ {
-		field := instType.Field(i)
-		fieldVal := v.FieldByName(field.Name)
-		required := field.Tag.Get("required")
+	eachTaggedField(reflect.ValueOf(r).Elem(), "body", func(field reflect.StructField, value reflect.Value, name string) {
+		if value.Kind() == reflect.String {
+			value.SetString(string(body))
+		} else {
+			value.SetBytes(body)
+		}
+	})
+	return nil
+}
 
-		if required == "true" {
-			if fieldVal.Kind() == reflect.String && fieldVal.String() == "" {
-				return errors.BadRequest(field.Name + " is required")
-			} else if fieldVal.Kind() == reflect.Uint && fieldVal.Uint() == 0 {
-				return errors.BadRequest(field.Name + " is required")
-			}
+func checkContentType(request *http.Request) *errors.AppError {
+	contentType := request.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, e := mime.ParseMediaType(contentType)
+	if e != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return errors.UnsupportedMediaType(contentType, "application/json")
+	}
+	return nil
+}
+
+// decodeError converts the errors of json.Decoder to bad requests pointing at the offending field or position.
+func decodeError(e error) *errors.AppError {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+	switch {
+	case e == nil:
+		return nil
+	case stdErrors.Is(e, io.EOF):
+		return errors.BadRequest("body is missing")
+	case stdErrors.Is(e, io.ErrUnexpectedEOF):
+		return errors.BadRequest("body contains malformed JSON, unexpected end of input")
+	case stdErrors.As(e, &syntaxErr):
+		return errors.BadRequest(fmt.Sprintf("body contains malformed JSON at position %d", syntaxErr.Offset))
+	case stdErrors.As(e, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
 		}
+		return errors.InvalidRequest(
+			fmt.Sprintf("body contains an invalid value at position %d", typeErr.Offset),
+			map[string]string{field: fmt.Sprintf("must be of type %s, got %s", typeErr.Type, typeErr.Value)},
+		)
+	case stdErrors.As(e, &maxBytesErr):
+		return errors.RequestEntityTooLarge(maxBytesErr.Limit)
+	case strings.HasPrefix(e.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(e.Error(), "json: unknown field "), `"`)
+		return errors.InvalidRequest("body contains unknown fields", map[string]string{field: "is not allowed"})
 	}
+	return errors.BadRequest("body could not be decoded")
+}
 
-	return nil
+// Nullable is a JSON field of a PATCH which can be left out, set to a value or set to null to clear it.
+// Its validate and pattern tags apply to the value.
+type Nullable[T any] struct {
+	Value *T
+	// Set reports whether the field was sent, null included
+	Set bool
 }
 
-type noBodyReq struct{}
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set, n.Value = true, nil
+	if string(data) == "null" {
+		return nil
+	}
+	var value T
+	if e := json.Unmarshal(data, &value); e != nil {
+		return e
+	}
+	n.Value = &value
+	return nil
+}
 
-func (n *noBodyReq) UnmarshalBody(request *http.Request) *errors.AppError {
-	err := fmt.Errorf("cannot accept body, %s", request.RequestURI)
-	return errors.InternalServerError(err)
+// ValueType returns the type of the value, for the request to be described as the value.
+func (n Nullable[T]) ValueType() reflect.Type {
+	return reflect.TypeFor[*T]()
 }
 
-type noPathReq struct{}
+func (n Nullable[T]) value() reflect.Value {
+	return reflect.ValueOf(n.Value)
+}
 
-func (n *noPathReq) UnmarshalPathValue(request *http.Request) *errors.AppError {
-	err := fmt.Errorf("cannot accept path values, %s", request.RequestURI)
-	return errors.InternalServerError(err)
+// nullable is implemented by every Nullable, whose value is validated in place of the field.
+type nullable interface {
+	value() reflect.Value
 }
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/common/configs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetBody(t *testing.T) {
+	newRequest := func(body, contentType string) *http.Request {
+		request := httptest.NewRequest("POST", "http://localhost:8080/api/provider/", strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		return request
+	}
+
+	t.Run("Successful Decode", func(t *testing.T) {
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(`{"name": "test", "url": "https://example.com"}`, "application/json; charset=utf-8"))
+		assert.Empty(t, err)
+		assert.Equal(t, "test", pr.Name)
+	})
+
+	t.Run("Missing Body", func(t *testing.T) {
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(``, "application/json"))
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(`{"name": "test",}`, "application/json"))
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+		assert.Contains(t, err.ApiErr.Detail, "position 17")
+	})
+
+	t.Run("Wrong Type", func(t *testing.T) {
+		var or OrderCreateRequest
+		err := getBody(&or, newRequest(`{"provider_id": "one", "receiver_id": 2}`, "application/json"))
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+		assert.Contains(t, err.ApiErr.Errors, "provider_id")
+	})
+
+	t.Run("Unsupported Content Type", func(t *testing.T) {
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(`name=test`, "application/x-www-form-urlencoded"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, err.Code)
+	})
+
+	t.Run("Body Too Large", func(t *testing.T) {
+		defaultSize := configs.MaxBodySize
+		configs.MaxBodySize = 10
+		defer func() { configs.MaxBodySize = defaultSize }()
+
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(`{"name": "test", "url": "https://example.com"}`, "application/json"))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, err.Code)
+	})
+
+	t.Run("Unknown Fields", func(t *testing.T) {
+		configs.RejectUnknownFields = true
+		defer func() { configs.RejectUnknownFields = false }()
+
+		var pr ProviderCreateRequest
+		err := getBody(&pr, newRequest(`{"name": "test", "url": "https://example.com", "extra": 1}`, "application/json"))
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+		assert.Equal(t, "is not allowed", err.ApiErr.Errors["extra"])
+	})
+}
+
+func TestGetPathValues(t *testing.T) {
+	t.Run("Invalid Number", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/api/order/abc/", http.NoBody)
+		request.SetPathValue("order_id", "abc")
+
+		var or OrderGetRequest
+		err := getPathValues(&or, request)
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+		assert.Contains(t, err.ApiErr.Errors, "order_id")
+	})
+}
+
+type testListRequest struct {
+	Pagination
+	ID       uint       `path:"id"`
+	Statuses []string   `query:"status"`
+	Since    *time.Time `query:"since"`
+	Strict   bool       `query:"strict" default:"true"`
+	Name     *string    `json:"name" validate:"min=2"`
+}
+
+func TestBind(t *testing.T) {
+	t.Run("Path And Query Values", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/5/?status=PENDING,PICKED_UP&status=DELIVERED&since=2025-04-23&limit=20", http.NoBody)
+		request.SetPathValue("id", "5")
+
+		var lr testListRequest
+		err := Bind(&lr, request)
+		assert.Empty(t, err)
+		assert.Equal(t, uint(5), lr.ID)
+		assert.Equal(t, []string{"PENDING", "PICKED_UP", "DELIVERED"}, lr.Statuses)
+		assert.Equal(t, time.Date(2025, 4, 23, 0, 0, 0, 0, time.UTC), *lr.Since)
+		assert.True(t, lr.Strict)
+		assert.Equal(t, uint64(20), lr.Limit)
+		assert.Equal(t, uint64(0), lr.Offset)
+		assert.Empty(t, lr.Name)
+	})
+
+	t.Run("Body With Path Values", func(t *testing.T) {
+		request := httptest.NewRequest("PATCH", "http://localhost:8080/test/5/", strings.NewReader(`{"name": "test"}`))
+		request.SetPathValue("id", "5")
+
+		var lr testListRequest
+		err := Bind(&lr, request)
+		assert.Empty(t, err)
+		assert.Equal(t, uint(5), lr.ID)
+		assert.Equal(t, "test", *lr.Name)
+	})
+
+	t.Run("Invalid Query Values", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/5/?since=yesterday&limit=-1", http.NoBody)
+		request.SetPathValue("id", "5")
+
+		var lr testListRequest
+		err := Bind(&lr, request)
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+		assert.Contains(t, err.ApiErr.Errors, "since")
+		assert.Contains(t, err.ApiErr.Errors, "limit")
+	})
+
+	t.Run("Validates Query Values", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://localhost:8080/test/5/?limit=1000", http.NoBody)
+		request.SetPathValue("id", "5")
+
+		var lr testListRequest
+		err := Bind(&lr, request)
+		assert.Equal(t, "must be at most 100", err.ApiErr.Errors["limit"])
+	})
+
+	t.Run("Raw Body", func(t *testing.T) {
+		request := httptest.NewRequest("POST", "http://localhost:8080/api/provider/5/service-areas/?replace=true", strings.NewReader("postal_code_from,postal_code_to,region\n11,14,north\n"))
+		request.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		request.SetPathValue("provider_id", "5")
+
+		var ir ServiceAreaImportRequest
+		err := Bind(&ir, request)
+		assert.Empty(t, err)
+		assert.Equal(t, uint(5), ir.ProviderID)
+		assert.True(t, ir.Replace)
+		assert.Contains(t, ir.CSV, "11,14,north")
+	})
+
+	t.Run("Raw Body Of Another Content Type", func(t *testing.T) {
+		request := httptest.NewRequest("POST", "http://localhost:8080/api/provider/5/service-areas/", strings.NewReader(`{}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.SetPathValue("provider_id", "5")
+
+		var ir ServiceAreaImportRequest
+		err := Bind(&ir, request)
+		assert.Equal(t, http.StatusUnsupportedMediaType, err.Code)
+		assert.Equal(t, "content type must be text/csv", err.ApiErr.Detail)
+	})
+}
@@ -0,0 +1,36 @@
+package domain
+
+// SelectionStrategy names the ways a provider is selected for an order sent without one.
+type SelectionStrategy struct {
+	Cheapest   string
+	Fastest    string
+	OnTime     string
+	RoundRobin string
+}
+
+func GetSelectionStrategy() *SelectionStrategy {
+	return &SelectionStrategy{
+		Cheapest:   "cheapest",
+		Fastest:    "fastest",
+		OnTime:     "on_time",
+		RoundRobin: "round_robin",
+	}
+}
+
+// ProviderScore is the score of a candidate provider of an order by the selection strategy, it is null
+// when the strategy had no data about the provider.
+type ProviderScore struct {
+	ProviderID uint     `json:"provider_id"`
+	Score      *float64 `json:"score"`
+}
+
+type ProviderOnTimeRate struct {
+	ProviderID uint    `json:"provider_id"`
+	Delivered  int64   `json:"delivered"`
+	OnTimeRate float64 `json:"on_time_rate"`
+}
+
+type ProviderLoad struct {
+	ProviderID uint  `json:"provider_id"`
+	Orders     int64 `json:"orders"`
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"encoding/csv"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ServiceArea is a range of postal codes served by a provider, postal codes are covered when their
+// prefix of the length of the bounds is within the range, so a prefix is a range with equal bounds.
+// Providers without service areas serve every postal code.
+type ServiceArea struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProviderID     uint      `json:"provider_id" gorm:"not null;index"`
+	Provider       *Provider `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostalCodeFrom string    `json:"postal_code_from" gorm:"size:10;not null"`
+	PostalCodeTo   string    `json:"postal_code_to" gorm:"size:10;not null"`
+	// Region is the identifier the provider uses for the area, it is only a label
+	Region    *string   `json:"region" gorm:"size:50"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// ServiceAreaImportRequest adds the service areas of the CSV body to the provider, or replaces its
+// service areas with them.
+type ServiceAreaImportRequest struct {
+	ProviderID uint   `path:"provider_id" json:"-"`
+	CSV        string `body:"text/csv" json:"-" validate:"required"`
+	Replace    bool   `query:"replace" default:"false"`
+}
+
+var (
+	serviceAreaHeader  = []string{"postal_code_from", "postal_code_to", "region"}
+	postalPrefixRegex  = regexp.MustCompile(`^[0-9]{1,10}$`)
+	maxServiceAreaRows = 5000
+)
+
+// ParseServiceAreas reads service areas from a CSV having the columns postal_code_from, postal_code_to
+// and region, the last two may be empty. Invalid rows are reported by their line.
+func ParseServiceAreas(body string) ([]*ServiceArea, map[string]string) {
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = len(serviceAreaHeader)
+	reader.TrimLeadingSpace = true
+
+	header, e := reader.Read()
+	if e != nil || !equalFold(header, serviceAreaHeader) {
+		return nil, map[string]string{"line 1": "must be the header " + strings.Join(serviceAreaHeader, ",")}
+	}
+
+	var areas []*ServiceArea
+	invalid := make(map[string]string)
+	for {
+		record, e := reader.Read()
+		if stdErrors.Is(e, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if stdErrors.As(e, &parseErr) {
+			invalid[fmt.Sprintf("line %d", parseErr.Line)] = parseErr.Err.Error()
+			if stdErrors.Is(parseErr.Err, csv.ErrFieldCount) {
+				continue
+			}
+			break
+		} else if e != nil {
+			return nil, map[string]string{"body": e.Error()}
+		}
+		line, _ := reader.FieldPos(0)
+		key := fmt.Sprintf("line %d", line)
+		if len(areas) == maxServiceAreaRows {
+			invalid[key] = fmt.Sprintf("at most %d service areas can be imported at once", maxServiceAreaRows)
+			break
+		}
+
+		area := &ServiceArea{PostalCodeFrom: record[0], PostalCodeTo: record[1]}
+		if area.PostalCodeTo == "" {
+			area.PostalCodeTo = area.PostalCodeFrom
+		}
+		if record[2] != "" {
+			area.Region = &record[2]
+		}
+		switch {
+		case !postalPrefixRegex.MatchString(area.PostalCodeFrom) || !postalPrefixRegex.MatchString(area.PostalCodeTo):
+			invalid[key] = "postal codes must have 1 to 10 digits"
+		case len(area.PostalCodeFrom) != len(area.PostalCodeTo):
+			invalid[key] = "postal_code_from and postal_code_to must have the same length"
+		case area.PostalCodeFrom > area.PostalCodeTo:
+			invalid[key] = "postal_code_from must not be after postal_code_to"
+		case area.Region != nil && len(*area.Region) > 50:
+			invalid[key] = "region must not be longer than 50 characters"
+		default:
+			areas = append(areas, area)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+	if len(areas) == 0 {
+		return nil, map[string]string{"line 2": "at least one service area is required"}
+	}
+	return areas, nil
+}
+
+func equalFold(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(a[i]), "\ufeff"), b[i]) {
+			return false
+		}
+	}
+	return true
+}
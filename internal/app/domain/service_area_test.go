@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseServiceAreas(t *testing.T) {
+	t.Run("Prefixes And Ranges", func(t *testing.T) {
+		areas, invalid := ParseServiceAreas("Postal_Code_From,postal_code_to,region\n11,,tehran\n3100,3199,\n")
+		assert.Empty(t, invalid)
+		assert.Len(t, areas, 2)
+		assert.Equal(t, "11", areas[0].PostalCodeTo)
+		assert.Equal(t, "tehran", *areas[0].Region)
+		assert.Empty(t, areas[1].Region)
+	})
+
+	t.Run("Invalid Rows By Line", func(t *testing.T) {
+		_, invalid := ParseServiceAreas("postal_code_from,postal_code_to,region\n11,12,\nab,,\n19,11,\n1,22,\n5\n")
+		assert.Equal(t, map[string]string{
+			"line 3": "postal codes must have 1 to 10 digits",
+			"line 4": "postal_code_from must not be after postal_code_to",
+			"line 5": "postal_code_from and postal_code_to must have the same length",
+			"line 6": "wrong number of fields",
+		}, invalid)
+	})
+
+	t.Run("Missing Header Or Rows", func(t *testing.T) {
+		_, invalid := ParseServiceAreas("11,12,north\n")
+		assert.Contains(t, invalid, "line 1")
+
+		_, invalid = ParseServiceAreas("postal_code_from,postal_code_to,region\n")
+		assert.Equal(t, "at least one service area is required", invalid["line 2"])
+	})
+}
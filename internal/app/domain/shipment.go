@@ -0,0 +1,88 @@
+package domain
+
+// Shipment is the order as it is booked with its provider, holding the contacts and the addresses
+// copied to the order when it was created.
+type Shipment struct {
+	OrderID uint    `json:"order_id"`
+	Product *string `json:"product"`
+	Parcel  *Parcel `json:"parcel,omitempty"`
+	// Parcels are tracked by the provider with their tracking numbers, their details are left out when unknown
+	Parcels []*ShipmentParcel `json:"parcels"`
+	Pickup  *ShipmentStop     `json:"pickup"`
+	Dropoff *ShipmentStop     `json:"dropoff"`
+}
+
+type ShipmentStop struct {
+	ContactName  *string  `json:"contact_name"`
+	ContactPhone string   `json:"contact_phone"`
+	Address      string   `json:"address"`
+	PostalCode   string   `json:"postal_code"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+type ShipmentParcel struct {
+	TrackingNumber string  `json:"tracking_number"`
+	Parcel         *Parcel `json:"parcel,omitempty"`
+}
+
+// ShipmentDelivery is the delivery of a booked shipment as its receiver changed it.
+type ShipmentDelivery struct {
+	OrderID         uint          `json:"order_id"`
+	TrackingNumbers []string      `json:"tracking_numbers"`
+	Slot            *DeliverySlot `json:"slot"`
+	HoldAtLocation  bool          `json:"hold_at_location"`
+	Instructions    *string       `json:"instructions"`
+}
+
+func NewShipment(order *Order) *Shipment {
+	shipment := &Shipment{
+		OrderID: order.ID,
+		Product: order.Product,
+		Pickup: &ShipmentStop{
+			ContactName:  order.PickupContactName,
+			ContactPhone: order.PickupContactPhone,
+			Address:      order.PickupAddress,
+			PostalCode:   order.PickupPostalCode,
+			Latitude:     order.PickupLatitude,
+			Longitude:    order.PickupLongitude,
+		},
+		Dropoff: &ShipmentStop{
+			ContactName:  order.DropoffContactName,
+			ContactPhone: order.DropoffContactPhone,
+			Address:      order.DropoffAddress,
+			PostalCode:   order.DropoffPostalCode,
+			Latitude:     order.DropoffLatitude,
+			Longitude:    order.DropoffLongitude,
+		},
+	}
+	if order.Parcel.Weight > 0 {
+		parcel := order.Parcel
+		shipment.Parcel = &parcel
+	}
+	for _, parcel := range order.Parcels {
+		item := &ShipmentParcel{TrackingNumber: parcel.TrackingNumber}
+		if parcel.Weight > 0 {
+			details := parcel.Parcel
+			item.Parcel = &details
+		}
+		shipment.Parcels = append(shipment.Parcels, item)
+	}
+	return shipment
+}
+
+func NewShipmentDelivery(order *Order) *ShipmentDelivery {
+	delivery := &ShipmentDelivery{
+		OrderID:         order.ID,
+		TrackingNumbers: make([]string, len(order.Parcels)),
+		HoldAtLocation:  order.HoldAtLocation,
+		Instructions:    order.DeliveryInstructions,
+	}
+	for i, parcel := range order.Parcels {
+		delivery.TrackingNumbers[i] = parcel.TrackingNumber
+	}
+	if order.DeliverySlotStart != nil && order.DeliverySlotEnd != nil {
+		delivery.Slot = &DeliverySlot{Start: *order.DeliverySlotStart, End: *order.DeliverySlotEnd}
+	}
+	return delivery
+}
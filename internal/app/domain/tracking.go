@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// OrderStatusEvent is a status the order took, the history of an order starts with the status it was created with.
+type OrderStatusEvent struct {
+	ID        uint      `json:"-" gorm:"primaryKey;autoIncrement"`
+	OrderID   uint      `json:"-" gorm:"not null;index"`
+	Status    string    `json:"status" gorm:"size:20;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// TrackingGetRequest looks an order up by its tracking code, which is case-insensitive.
+type TrackingGetRequest struct {
+	Code string `path:"code" json:"-" pattern:"^[0-9A-Za-z]{12}$"`
+}
+
+// Tracking is the public view of an order for anyone holding its tracking code, it leaves out the parties,
+// the addresses and the contacts of the order.
+type Tracking struct {
+	TrackingCode      string              `json:"tracking_code"`
+	Status            string              `json:"status"`
+	ProviderName      string              `json:"provider_name"`
+	EstimatedDelivery DeliveryEstimate    `json:"estimated_delivery"`
+	PickedUpDate      *time.Time          `json:"picked_up_date"`
+	DeliveryDate      *time.Time          `json:"delivery_date"`
+	Parcels           []*TrackedParcel    `json:"parcels"`
+	History           []*OrderStatusEvent `json:"history"`
+	CreatedAt         time.Time           `json:"created_at"`
+}
+
+type TrackedParcel struct {
+	Number         uint   `json:"number"`
+	TrackingNumber string `json:"tracking_number"`
+	Status         string `json:"status"`
+}
+
+// NewTracking returns the public view of the order, holding its parcels, its history and its delivery estimate.
+func NewTracking(order *Order, provider *Provider) *Tracking {
+	tracking := &Tracking{
+		TrackingCode:      order.TrackingCode,
+		Status:            order.Status,
+		ProviderName:      provider.Name,
+		EstimatedDelivery: order.EstimatedDelivery.AsOf(time.Now()),
+		PickedUpDate:      order.PickedUpDate,
+		DeliveryDate:      order.DeliveryDate,
+		Parcels:           make([]*TrackedParcel, len(order.Parcels)),
+		History:           order.History,
+		CreatedAt:         order.CreatedAt,
+	}
+	for i, parcel := range order.Parcels {
+		tracking.Parcels[i] = &TrackedParcel{Number: parcel.Number, TrackingNumber: parcel.TrackingNumber, Status: parcel.Status}
+	}
+	return tracking
+}
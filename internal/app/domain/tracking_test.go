@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewTracking(t *testing.T) {
+	phone := "+989121234567"
+	order := &Order{
+		TrackingCode: "7K2M9QX4BD1R", Status: GetOrderStatus().PickedUp,
+		PickupContactPhone: phone, PickupAddress: "Valiasr St, Tehran", DropoffAddress: "somewhere",
+		Parcels: []*OrderParcel{{Number: 1, TrackingNumber: "X4BD1R7K2M9Q", Status: GetOrderStatus().PickedUp}},
+		History: []*OrderStatusEvent{{Status: GetOrderStatus().ProviderSeen}, {Status: GetOrderStatus().PickedUp}},
+	}
+	estimated := time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)
+	order.EstimatedDelivery.Date = &estimated
+
+	tracking := NewTracking(order, &Provider{Name: "test-provider"})
+	assert.Equal(t, "test-provider", tracking.ProviderName)
+	assert.Equal(t, "X4BD1R7K2M9Q", tracking.Parcels[0].TrackingNumber)
+	assert.Len(t, tracking.History, 2)
+	// the estimate of the stalled order is not in the past
+	assert.False(t, tracking.EstimatedDelivery.Date.Before(date(time.Now())))
+
+	body, e := json.Marshal(tracking)
+	assert.NoError(t, e)
+	assert.NotContains(t, string(body), phone)
+	assert.NotContains(t, string(body), "Valiasr")
+}
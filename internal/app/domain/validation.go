@@ -0,0 +1,280 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Fields are validated with a `validate` tag holding comma separated rules:
+//
+//	required    the value must not be zero, blank strings and nil pointers are zero too
+//	notblank    a pointer which is set must not point to a blank string, and the other rules check the zero
+//	            values it points to, for the fields of a PATCH which may be left out but not cleared
+//	min=N       minimum length of strings and slices, or minimum value of numbers
+//	max=N       maximum length of strings and slices, or maximum value of numbers
+//	url         an absolute http or https url
+//	e164        a phone number in E.164 format, like +989121234567
+//	oneof=a b   one of the values separated by spaces
+//
+// and an optional `pattern` tag holding a regular expression the value must match.
+// Rules other than required are skipped for zero values. Nested structs, pointers and
+// slices are validated as well, with their violations keyed by the json path of the field.
+
+// E164Pattern matches the phone numbers accepted by the e164 rule.
+const E164Pattern = `^\+[1-9][0-9]{7,14}$`
+
+var e164Regex = regexp.MustCompile(E164Pattern)
+
+var patterns sync.Map
+
+func validate(r any) map[string]string {
+	violations := make(map[string]string)
+	validateStruct(reflect.ValueOf(r), "", violations)
+	return violations
+}
+
+func validateStruct(v reflect.Value, prefix string, violations map[string]string) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			validateStruct(v.Field(i), prefix, violations)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + jsonName(field)
+		if msg := validateField(field, v.Field(i)); msg != "" {
+			violations[name] = msg
+			continue
+		}
+		validateNested(v.Field(i), name, violations)
+	}
+}
+
+func validateNested(v reflect.Value, name string, violations map[string]string) {
+	if n, ok := v.Interface().(nullable); ok {
+		v = n.value()
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, name+".", violations)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateStruct(v.Index(i), fmt.Sprintf("%s[%d].", name, i), violations)
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	if name := field.Tag.Get("path"); name != "" {
+		return name
+	}
+	if name := field.Tag.Get("query"); name != "" {
+		return name
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func validateField(field reflect.StructField, v reflect.Value) string {
+	tag := field.Tag.Get("validate")
+	pattern := field.Tag.Get("pattern")
+	if tag == "" && pattern == "" {
+		return ""
+	}
+	rules := strings.Split(tag, ",")
+
+	if n, ok := v.Interface().(nullable); ok {
+		v = n.value()
+	}
+	set := false
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			break
+		}
+		v, set = v.Elem(), true
+	}
+	if isBlank(v) {
+		if contains(rules, "required") {
+			return "is required"
+		}
+		if !set || !contains(rules, "notblank") {
+			return ""
+		}
+		if v.Kind() == reflect.String {
+			return "must not be blank"
+		}
+	}
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		var msg string
+		switch name {
+		case "", "required", "notblank":
+		case "min":
+			msg = checkBound(v, arg, func(n, bound float64) bool { return n >= bound }, "at least")
+		case "max":
+			msg = checkBound(v, arg, func(n, bound float64) bool { return n <= bound }, "at most")
+		case "url":
+			if u, e := url.ParseRequestURI(v.String()); e != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+				msg = "must be a valid http or https url"
+			}
+		case "e164":
+			if !e164Regex.MatchString(v.String()) {
+				msg = "must be a phone number in E.164 format, like +989121234567"
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			if !contains(options, fmt.Sprint(v.Interface())) {
+				msg = "must be one of: " + strings.Join(options, ", ")
+			}
+		}
+		if msg != "" {
+			return msg
+		}
+	}
+
+	if pattern != "" && !compilePattern(pattern).MatchString(fmt.Sprint(v.Interface())) {
+		return "has an invalid format"
+	}
+	return ""
+}
+
+// CheckTags returns an error for the first unknown rule, invalid bound or invalid pattern in the tags of the
+// struct type t and of the structs nested in it. Such rules are skipped by the validation, so the request
+// types are checked once when the server starts.
+func CheckTags(t reflect.Type) error {
+	return checkTags(t, make(map[reflect.Type]bool))
+}
+
+func checkTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tag := field.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, arg, _ := strings.Cut(rule, "=")
+				switch name {
+				case "", "required", "notblank", "url", "e164":
+				case "min", "max":
+					if _, e := strconv.ParseFloat(arg, 64); e != nil {
+						return fmt.Errorf("invalid bound %q of rule %s on field %s.%s", arg, name, t.Name(), field.Name)
+					}
+				case "oneof":
+					if len(strings.Fields(arg)) == 0 {
+						return fmt.Errorf("rule oneof without values on field %s.%s", t.Name(), field.Name)
+					}
+				default:
+					return fmt.Errorf("unknown validation rule %q on field %s.%s", name, t.Name(), field.Name)
+				}
+			}
+		}
+		if pattern := field.Tag.Get("pattern"); pattern != "" {
+			if _, e := regexp.Compile(pattern); e != nil {
+				return fmt.Errorf("invalid pattern on field %s.%s: %w", t.Name(), field.Name, e)
+			}
+		}
+		if err := checkTags(field.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		// the fields of a struct are validated on their own
+		return false
+	}
+	return v.IsZero()
+}
+
+func checkBound(v reflect.Value, arg string, ok func(n, bound float64) bool, word string) string {
+	bound, e := strconv.ParseFloat(arg, 64)
+	if e != nil {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if !ok(float64(utf8.RuneCountInString(v.String())), bound) {
+			return fmt.Sprintf("must be %s %s characters long", word, arg)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if !ok(float64(v.Len()), bound) {
+			return fmt.Sprintf("must have %s %s items", word, arg)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !ok(float64(v.Int()), bound) {
+			return fmt.Sprintf("must be %s %s", word, arg)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !ok(float64(v.Uint()), bound) {
+			return fmt.Sprintf("must be %s %s", word, arg)
+		}
+	case reflect.Float32, reflect.Float64:
+		if !ok(v.Float(), bound) {
+			return fmt.Sprintf("must be %s %s", word, arg)
+		}
+	}
+	return ""
+}
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
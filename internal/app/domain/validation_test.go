@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	PostalCode string `json:"postal_code" validate:"required" pattern:"^[0-9]{5}$"`
+}
+
+type testRequest struct {
+	Name     string         `json:"name" validate:"required,min=2,max=5"`
+	Phone    *string        `json:"phone" validate:"e164"`
+	Url      string         `json:"url" validate:"url"`
+	Kind     string         `json:"kind" validate:"oneof=home work"`
+	Count    uint           `json:"count" validate:"max=10"`
+	Address  *testAddress   `json:"address" validate:"required"`
+	Previous []*testAddress `json:"previous"`
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Valid Request", func(t *testing.T) {
+		phone := "+989121234567"
+		violations := validate(&testRequest{
+			Name:     "name",
+			Phone:    &phone,
+			Url:      "https://example.com/status",
+			Kind:     "home",
+			Count:    10,
+			Address:  &testAddress{PostalCode: "12345"},
+			Previous: []*testAddress{{PostalCode: "54321"}},
+		})
+		assert.Empty(t, violations)
+	})
+
+	t.Run("Reports Every Violation", func(t *testing.T) {
+		phone := "0912"
+		violations := validate(&testRequest{
+			Name:     "   ",
+			Phone:    &phone,
+			Url:      "not a url",
+			Kind:     "other",
+			Count:    11,
+			Previous: []*testAddress{{PostalCode: "1"}},
+		})
+		assert.Equal(t, map[string]string{
+			"name":                    "is required",
+			"phone":                   "must be a phone number in E.164 format, like +989121234567",
+			"url":                     "must be a valid http or https url",
+			"kind":                    "must be one of: home, work",
+			"count":                   "must be at most 10",
+			"address":                 "is required",
+			"previous[0].postal_code": "has an invalid format",
+		}, violations)
+	})
+
+	t.Run("Nested Struct", func(t *testing.T) {
+		violations := validate(&testRequest{Name: "n", Address: &testAddress{}})
+		assert.Equal(t, map[string]string{
+			"name":                "must be at least 2 characters long",
+			"address.postal_code": "is required",
+		}, violations)
+	})
+}
+
+func TestValidate_NotBlank(t *testing.T) {
+	type patchRequest struct {
+		Street *string `json:"street" validate:"notblank,min=3"`
+		Postal *string `json:"postal_code" validate:"notblank" pattern:"^[0-9]{5}$"`
+		Weight *uint   `json:"weight" validate:"notblank,min=1"`
+	}
+
+	t.Run("Left Out", func(t *testing.T) {
+		assert.Empty(t, validate(&patchRequest{}))
+	})
+
+	t.Run("Set To Blank Values", func(t *testing.T) {
+		blank, zero := " ", uint(0)
+		assert.Equal(t, map[string]string{
+			"street":      "must not be blank",
+			"postal_code": "must not be blank",
+			"weight":      "must be at least 1",
+		}, validate(&patchRequest{Street: &blank, Postal: &blank, Weight: &zero}))
+	})
+}
+
+func TestValidate_Nullable(t *testing.T) {
+	type patchRequest struct {
+		Url Nullable[string] `json:"url" validate:"notblank,url"`
+	}
+
+	for name, test := range map[string]struct {
+		body       string
+		set        bool
+		violations map[string]string
+	}{
+		"Left Out":      {body: `{}`},
+		"Null":          {body: `{"url": null}`, set: true},
+		"Valid Value":   {body: `{"url": "https://example.com/quote"}`, set: true},
+		"Blank Value":   {body: `{"url": ""}`, set: true, violations: map[string]string{"url": "must not be blank"}},
+		"Invalid Value": {body: `{"url": "not a url"}`, set: true, violations: map[string]string{"url": "must be a valid http or https url"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var request patchRequest
+			assert.NoError(t, json.Unmarshal([]byte(test.body), &request))
+			assert.Equal(t, test.set, request.Url.Set)
+			if test.violations == nil {
+				assert.Empty(t, validate(&request))
+			} else {
+				assert.Equal(t, test.violations, validate(&request))
+			}
+		})
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	assert.NoError(t, CheckTags(reflect.TypeOf(testRequest{})))
+
+	for name, request := range map[string]any{
+		"Unknown Rule": struct {
+			Name string `validate:"requird"`
+		}{},
+		"Invalid Bound": struct {
+			Name string `validate:"max=ten"`
+		}{},
+		"Invalid Pattern": struct {
+			Name string `pattern:"[0-9"`
+		}{},
+		"Nested": struct {
+			Addresses []*struct {
+				Code string `validate:"oneof"`
+			}
+		}{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, CheckTags(reflect.TypeOf(request)))
+		})
+	}
+}
@@ -4,43 +4,132 @@ import (
 	"context"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/common/errors"
+	"time"
 )
 
 type Service interface {
-	HealthCheck(ctx context.Context) (any, *errors.AppError)
+	Liveness(ctx context.Context) (*domain.HealthReport, *errors.AppError)
+	Readiness(ctx context.Context) (*domain.HealthReport, *errors.AppError)
 
-	GetProviders(ctx context.Context) ([]*domain.Provider, *errors.AppError)
+	GetProviders(ctx context.Context, request *domain.ProviderListRequest) (*domain.Page[*domain.Provider], *errors.AppError)
 	GetProvidersMeanDelTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError)
 	CreateProvider(ctx context.Context, request *domain.ProviderCreateRequest) (*domain.Provider, *errors.AppError)
+	GetProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError)
+	UpdateProvider(ctx context.Context, request *domain.ProviderUpdateRequest) (*domain.Provider, *errors.AppError)
+	VerifyProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError)
+	ListServiceAreas(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.ServiceArea, *errors.AppError)
+	ImportServiceAreas(ctx context.Context, request *domain.ServiceAreaImportRequest) ([]*domain.ServiceArea, *errors.AppError)
+	ListTariffs(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.Tariff, *errors.AppError)
+	UpdateTariffs(ctx context.Context, request *domain.TariffsUpdateRequest) ([]*domain.Tariff, *errors.AppError)
+
+	CreateQuote(ctx context.Context, request *domain.QuoteCreateRequest) (*domain.Quote, *errors.AppError)
 
 	CreateCustomer(ctx context.Context, request *domain.CustomerCreateRequest) (*domain.Customer, *errors.AppError)
-	GetCustomerToken(ctx context.Context, request *domain.CustomerTokenRequest) (any, *errors.AppError)
+	GetCustomerToken(ctx context.Context, request *domain.CustomerTokenRequest) (*domain.CustomerToken, *errors.AppError)
+	GetProfile(ctx context.Context) (*domain.Customer, *errors.AppError)
+	UpdateProfile(ctx context.Context, request *domain.CustomerUpdateRequest) (*domain.Customer, *errors.AppError)
+	VerifyPhoneNumber(ctx context.Context, request *domain.PhoneVerifyRequest) (*domain.Customer, *errors.AppError)
+
+	ListAddresses(ctx context.Context) ([]*domain.Address, *errors.AppError)
+	CreateAddress(ctx context.Context, request *domain.AddressCreateRequest) (*domain.Address, *errors.AppError)
+	GetAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError)
+	UpdateAddress(ctx context.Context, request *domain.AddressUpdateRequest) (*domain.Address, *errors.AppError)
+	DeleteAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError)
 
 	CreateOrder(ctx context.Context, request *domain.OrderCreateRequest) (*domain.Order, *errors.AppError)
 	GetOrder(ctx context.Context, request *domain.OrderGetRequest) (*domain.Order, *errors.AppError)
+	GetTracking(ctx context.Context, request *domain.TrackingGetRequest) (*domain.Tracking, *errors.AppError)
+	ListIncomingOrders(ctx context.Context, request *domain.OrderListRequest) (*domain.Page[*domain.Order], *errors.AppError)
+	ListDeliverySlots(ctx context.Context, request *domain.OrderGetRequest) ([]*domain.DeliverySlot, *errors.AppError)
+	UpdateDelivery(ctx context.Context, request *domain.OrderDeliveryUpdateRequest) (*domain.Order, *errors.AppError)
 
 	ScheduleUpdateOrderStatus()
 }
 
 type Repo interface {
-	Ready() bool
+	Ping(ctx context.Context) *errors.AppError
+	GetMigrationVersion(ctx context.Context) (*domain.MigrationVersion, *errors.AppError)
 	Close()
 
-	GetOrder(ctx context.Context, orderID, senderID uint) (*domain.Order, *errors.AppError)
-	GetOrderWithForeignObjects(ctx context.Context, orderID, senderID uint) (*domain.Order, *errors.AppError)
-	CreateOrder(ctx context.Context, userID, receiverID, providerID uint, product *string) (*domain.Order, *errors.AppError)
+	// GetOrder returns the order sent by or to the customer.
+	GetOrder(ctx context.Context, orderID, userID uint) (*domain.Order, *errors.AppError)
+	GetOrderWithForeignObjects(ctx context.Context, orderID, userID uint) (*domain.Order, *errors.AppError)
+	ListIncomingOrders(ctx context.Context, receiverID uint, request *domain.OrderListRequest) ([]*domain.Order, int64, *errors.AppError)
+	CreateOrder(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError)
+	PreviewOrder(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError)
 	GetOngoingOrders(ctx context.Context) ([]*domain.Order, *errors.AppError)
+	GetUnbookedOrders(ctx context.Context, createdBefore time.Time) ([]*domain.Order, *errors.AppError)
+	UpdateOrderBooking(ctx context.Context, orderID uint, status string, attempts uint) *errors.AppError
 	UpdateOrderStatus(ctx context.Context, orderID uint, status string) (*domain.Order, *errors.AppError)
+	GetOrderParcels(ctx context.Context, orderID uint) ([]*domain.OrderParcel, *errors.AppError)
+	UpdateParcelStatus(ctx context.Context, parcelID uint, status string) (*domain.Order, *errors.AppError)
+	GetTrackedOrder(ctx context.Context, trackingCode string) (*domain.Order, *errors.AppError)
 	UpdateOrderNotification(ctx context.Context, orderID uint) *errors.AppError
+	// UpdateOrderDelivery saves the delivery of the order as long as it is not picked up.
+	UpdateOrderDelivery(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError)
+	// RevertOrderDelivery saves the previous delivery of the order back, whatever its status.
+	RevertOrderDelivery(ctx context.Context, order *domain.Order) *errors.AppError
+	GetDeliverySamples(ctx context.Context, providerID uint, since time.Time, limit int) ([]*domain.DeliverySample, *errors.AppError)
+	UpdateOrderEstimate(ctx context.Context, orderID uint, estimate *domain.DeliveryEstimate) *errors.AppError
 	GetProvidersMeanDeliveryTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError)
+	GetProvidersOnTimeRate(ctx context.Context, days int) ([]*domain.ProviderOnTimeRate, *errors.AppError)
+	GetProvidersLoad(ctx context.Context, since time.Time) ([]*domain.ProviderLoad, *errors.AppError)
 
 	GetCustomer(ctx context.Context, userID uint) (*domain.Customer, *errors.AppError)
 	CreateCustomer(ctx context.Context, name, phone, addr, postalCode *string) (*domain.Customer, *errors.AppError)
+	UpdateCustomer(ctx context.Context, customerID uint, update *domain.CustomerUpdate) (*domain.Customer, *errors.AppError)
+	GetCustomerAudits(ctx context.Context, customerID uint) ([]*domain.CustomerAudit, *errors.AppError)
+	CreatePhoneVerification(ctx context.Context, customerID uint, phone, codeHash string, ttl time.Duration) (*domain.PhoneVerification, *errors.AppError)
+	GetPhoneVerification(ctx context.Context, customerID uint) (*domain.PhoneVerification, *errors.AppError)
+	IncrementPhoneVerificationAttempts(ctx context.Context, verificationID uint) *errors.AppError
+	DeletePhoneVerification(ctx context.Context, verificationID uint) *errors.AppError
+
+	ListAddresses(ctx context.Context, customerID uint) ([]*domain.Address, *errors.AppError)
+	GetAddress(ctx context.Context, addressID, customerID uint) (*domain.Address, *errors.AppError)
+	CreateAddress(ctx context.Context, address *domain.Address) (*domain.Address, *errors.AppError)
+	UpdateAddress(ctx context.Context, customerID uint, update *domain.AddressUpdateRequest) (*domain.Address, *errors.AppError)
+	DeleteAddress(ctx context.Context, addressID, customerID uint) (*domain.Address, *errors.AppError)
 
 	GetProvider(ctx context.Context, providerID uint) (*domain.Provider, *errors.AppError)
 	GetAllProviders(ctx context.Context) ([]*domain.Provider, *errors.AppError)
-	CreateProvider(ctx context.Context, name, url *string) (*domain.Provider, *errors.AppError)
+	ListProviders(ctx context.Context, request *domain.ProviderListRequest) ([]*domain.Provider, int64, *errors.AppError)
+	CreateProvider(ctx context.Context, name, url, quoteUrl *string) (*domain.Provider, *errors.AppError)
+	UpdateProvider(ctx context.Context, update *domain.ProviderUpdateRequest) (*domain.Provider, *errors.AppError)
+	UpdateProviderVerification(ctx context.Context, providerID uint, url, status string, detail *string) (*domain.Provider, *errors.AppError)
+	ListServiceAreas(ctx context.Context, providerID uint) ([]*domain.ServiceArea, *errors.AppError)
+	ImportServiceAreas(ctx context.Context, providerID uint, areas []*domain.ServiceArea, replace bool) ([]*domain.ServiceArea, *errors.AppError)
+	ListTariffs(ctx context.Context, providerID uint) ([]*domain.Tariff, *errors.AppError)
+	ReplaceTariffs(ctx context.Context, providerID uint, tariffs []*domain.Tariff) ([]*domain.Tariff, *errors.AppError)
+	GetQuotableProviders(ctx context.Context, pickupPostalCode, dropoffPostalCode string) ([]*domain.Provider, *errors.AppError)
+
+	CreateQuote(ctx context.Context, quote *domain.Quote) (*domain.Quote, *errors.AppError)
+	GetQuote(ctx context.Context, quoteID, customerID uint) (*domain.Quote, *errors.AppError)
 
 	CreateOrUpdatePeriodicTask(ctx context.Context, name string, interval int, failed bool, e *string) (*domain.PeriodicTask, *errors.AppError)
+	GetPeriodicTask(ctx context.Context, name string) (*domain.PeriodicTask, *errors.AppError)
 	GetOrCreatePeriodicTask(ctx context.Context, name string, interval int) (*domain.PeriodicTask, *errors.AppError)
 }
+
+// ProviderGateway sends the requests of the service to the providers.
+type ProviderGateway interface {
+	Ping(ctx context.Context, provider *domain.Provider) *errors.AppError
+	// GetStatuses reads the statuses of the parcel with the tracking number from the provider.
+	GetStatuses(ctx context.Context, provider *domain.Provider, trackingNumber string) (*domain.ProviderUrlResponse, *errors.AppError)
+	BookShipment(ctx context.Context, provider *domain.Provider, shipment *domain.Shipment) *errors.AppError
+	// UpdateDelivery sends the delivery of a booked shipment changed by its receiver.
+	UpdateDelivery(ctx context.Context, provider *domain.Provider, delivery *domain.ShipmentDelivery) *errors.AppError
+	// Quote asks the quote url of the provider for the price of the parcel.
+	Quote(ctx context.Context, provider *domain.Provider, request *domain.ProviderQuoteRequest) (*domain.ProviderQuote, *errors.AppError)
+	// Verify checks the provider responds with the statuses of the order with the reference, or of any order without one.
+	Verify(ctx context.Context, provider *domain.Provider, reference string) *errors.AppError
+}
+
+type RateLimitStore interface {
+	TakeToken(ctx context.Context, key string, limit *domain.RateLimit) (*domain.RateLimitResult, *errors.AppError)
+}
+
+type IdempotencyStore interface {
+	StartIdempotentRequest(ctx context.Context, userID uint, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, bool, *errors.AppError)
+	CompleteIdempotentRequest(ctx context.Context, recordID uint, code int, headers map[string]string, body []byte) *errors.AppError
+	DeleteIdempotentRequest(ctx context.Context, recordID uint) *errors.AppError
+}
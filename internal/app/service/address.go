@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+)
+
+func (s *LogisticService) ListAddresses(ctx context.Context) ([]*domain.Address, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	return s.repo.ListAddresses(ctx, userID)
+}
+
+func (s *LogisticService) CreateAddress(ctx context.Context, request *domain.AddressCreateRequest) (*domain.Address, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	if err := validateCoordinates(request.Latitude, request.Longitude); err != nil {
+		return nil, err
+	}
+
+	address, err := s.repo.CreateAddress(ctx, &domain.Address{
+		CustomerID: userID,
+		Label:      request.Label,
+		IsDefault:  request.IsDefault,
+		City:       request.City,
+		Street:     request.Street,
+		Unit:       request.Unit,
+		PostalCode: request.PostalCode,
+		Latitude:   request.Latitude,
+		Longitude:  request.Longitude,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "address created", "customer_id", userID, "address_id", address.ID)
+	return address, nil
+}
+
+func (s *LogisticService) GetAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	return s.repo.GetAddress(ctx, request.AddressID, userID)
+}
+
+func (s *LogisticService) UpdateAddress(ctx context.Context, request *domain.AddressUpdateRequest) (*domain.Address, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	if err := validateCoordinates(request.Latitude, request.Longitude); err != nil {
+		return nil, err
+	}
+
+	address, err := s.repo.UpdateAddress(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "address updated", "customer_id", userID, "address_id", address.ID)
+	return address, nil
+}
+
+// DeleteAddress deletes the address and returns it, orders sent from or to it keep their copy of the address.
+func (s *LogisticService) DeleteAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+
+	address, err := s.repo.DeleteAddress(ctx, request.AddressID, userID)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "address deleted", "customer_id", userID, "address_id", address.ID)
+	return address, nil
+}
+
+// validateCoordinates requires the latitude and the longitude to be given together.
+func validateCoordinates(latitude, longitude *float64) *errors.AppError {
+	switch {
+	case latitude != nil && longitude == nil:
+		return errors.ValidationError(map[string]string{"longitude": "is required along with latitude"})
+	case latitude == nil && longitude != nil:
+		return errors.ValidationError(map[string]string{"latitude": "is required along with longitude"})
+	}
+	return nil
+}
+
+// checkAddressOwner reports the field of the request holding an address which is not an address of the customer.
+// Missing addresses and addresses of other customers get the same violation, so the addresses of a receiver cannot
+// be told apart from the ones which do not exist.
+func (s *LogisticService) checkAddressOwner(ctx context.Context, field string, addressID *uint, customerID uint) *errors.AppError {
+	if addressID == nil {
+		return nil
+	}
+	_, err := s.repo.GetAddress(ctx, *addressID, customerID)
+	if err != nil && err.Code == http.StatusNotFound {
+		return errors.ValidationError(map[string]string{field: "is not a valid address"})
+	}
+	return err
+}
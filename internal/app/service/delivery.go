@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/logger"
+	"time"
+)
+
+func (s *LogisticService) ListIncomingOrders(ctx context.Context, request *domain.OrderListRequest) (*domain.Page[*domain.Order], *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	orders, total, err := s.repo.ListIncomingOrders(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	estimatesAsOfToday(orders...)
+	return domain.NewPage(orders, request.Pagination, total), nil
+}
+
+func (s *LogisticService) ListDeliverySlots(ctx context.Context, request *domain.OrderGetRequest) ([]*domain.DeliverySlot, *errors.AppError) {
+	if _, err := s.receivedOrder(ctx, request.OrderID); err != nil {
+		return nil, err
+	}
+	return domain.OfferDeliverySlots(time.Now()), nil
+}
+
+// UpdateDelivery changes the delivery of the order, saving it as long as the order is not picked up and then
+// sending it to the provider. The previous delivery is saved back when the provider rejects the change, so the
+// order keeps the delivery the provider has. No lock is held while the provider is called.
+func (s *LogisticService) UpdateDelivery(ctx context.Context, request *domain.OrderDeliveryUpdateRequest) (*domain.Order, *errors.AppError) {
+	ctx = logger.WithOrderID(ctx, request.OrderID)
+	order, err := s.receivedOrder(ctx, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	previous := *order
+	if invalid := request.Apply(order, time.Now()); invalid != nil {
+		return nil, errors.ValidationError(invalid)
+	}
+
+	if order, err = s.repo.UpdateOrderDelivery(ctx, order); err != nil {
+		return nil, err
+	}
+	if err = s.providers.UpdateDelivery(ctx, order.Provider, domain.NewShipmentDelivery(order)); err != nil {
+		if revertErr := s.repo.RevertOrderDelivery(ctx, &previous); revertErr != nil {
+			slog.ErrorContext(ctx, "could not revert the delivery rejected by the provider", "error", revertErr.Err)
+		}
+		return nil, err
+	}
+	estimatesAsOfToday(order)
+	slog.InfoContext(ctx, "delivery updated", "hold_at_location", order.HoldAtLocation,
+		"delivery_slot_start", order.DeliverySlotStart)
+	return order, nil
+}
+
+// receivedOrder returns the order sent to the authorized customer with its provider and its parcels, as long as
+// its delivery can be changed. The sender of the order can read it but not change its delivery.
+func (s *LogisticService) receivedOrder(ctx context.Context, orderID uint) (*domain.Order, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	order, err := s.repo.GetOrderWithForeignObjects(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.ReceiverID != userID {
+		return nil, errors.Forbidden("only the receiver of the order can change its delivery")
+	}
+	if !order.DeliveryChangeable() {
+		return nil, errors.Conflict(errors.CodeOrderPickedUp, "the order is picked up, its delivery cannot be changed")
+	}
+	return order, nil
+}
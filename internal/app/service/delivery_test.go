@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// deliveryRepo holds a single order received by the customer and keeps the deliveries saved for it.
+type deliveryRepo struct {
+	ports.Repo
+	order    *domain.Order
+	saved    []bool
+	reverted []bool
+}
+
+func (r *deliveryRepo) GetOrderWithForeignObjects(ctx context.Context, orderID, userID uint) (*domain.Order, *errors.AppError) {
+	order := *r.order
+	return &order, nil
+}
+
+func (r *deliveryRepo) ListIncomingOrders(ctx context.Context, userID uint, request *domain.OrderListRequest) ([]*domain.Order, int64, *errors.AppError) {
+	order := *r.order
+	return []*domain.Order{&order}, 1, nil
+}
+
+func (r *deliveryRepo) UpdateOrderDelivery(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError) {
+	r.saved = append(r.saved, order.HoldAtLocation)
+	return order, nil
+}
+
+func (r *deliveryRepo) RevertOrderDelivery(ctx context.Context, order *domain.Order) *errors.AppError {
+	r.reverted = append(r.reverted, order.HoldAtLocation)
+	return nil
+}
+
+// deliveryGateway answers every change of a delivery with the error.
+type deliveryGateway struct {
+	ports.ProviderGateway
+	err *errors.AppError
+}
+
+func (g *deliveryGateway) UpdateDelivery(ctx context.Context, provider *domain.Provider, delivery *domain.ShipmentDelivery) *errors.AppError {
+	return g.err
+}
+
+func TestUpdateDelivery(t *testing.T) {
+	ctx := context.WithValue(context.Background(), configs.UserIDKey, uint(2))
+	hold := true
+	request := &domain.OrderDeliveryUpdateRequest{OrderID: 3, HoldAtLocation: &hold}
+	newRepo := func() *deliveryRepo {
+		return &deliveryRepo{order: &domain.Order{
+			ID:         3,
+			ReceiverID: 2,
+			Status:     domain.GetOrderStatus().Pending,
+			Provider:   &domain.Provider{ID: 1},
+		}}
+	}
+
+	t.Run("Accepted By The Provider", func(t *testing.T) {
+		repo := newRepo()
+		s := NewLogisticService(repo, &deliveryGateway{})
+
+		order, err := s.UpdateDelivery(ctx, request)
+		assert.Nil(t, err)
+		assert.True(t, order.HoldAtLocation)
+		assert.Equal(t, []bool{true}, repo.saved)
+		assert.Empty(t, repo.reverted)
+	})
+
+	t.Run("Rejected By The Provider", func(t *testing.T) {
+		repo := newRepo()
+		rejected := errors.New(http.StatusBadGateway, errors.CodeProviderRejected, "the provider rejected the change of the delivery", nil)
+		s := NewLogisticService(repo, &deliveryGateway{err: rejected})
+
+		_, err := s.UpdateDelivery(ctx, request)
+		assert.Equal(t, http.StatusBadGateway, err.Code)
+		assert.Equal(t, []bool{true}, repo.saved)
+		assert.Equal(t, []bool{false}, repo.reverted)
+	})
+}
+
+func TestOrdersEstimateAsOfToday(t *testing.T) {
+	ctx := context.WithValue(context.Background(), configs.UserIDKey, uint(2))
+	past := time.Now().AddDate(0, 0, -3)
+	repo := &deliveryRepo{order: &domain.Order{
+		ID:                3,
+		ReceiverID:        2,
+		EstimatedDelivery: domain.DeliveryEstimate{Earliest: &past, Date: &past, Latest: &past},
+	}}
+	s := NewLogisticService(repo, nil)
+
+	order, err := s.GetOrder(ctx, &domain.OrderGetRequest{OrderID: 3})
+	assert.Nil(t, err)
+	assert.False(t, order.EstimatedDelivery.Date.Before(past.AddDate(0, 0, 1)))
+
+	page, err := s.ListIncomingOrders(ctx, &domain.OrderListRequest{})
+	assert.Nil(t, err)
+	assert.False(t, page.Items[0].EstimatedDelivery.Earliest.Before(past.AddDate(0, 0, 1)))
+}
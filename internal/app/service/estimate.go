@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"time"
+)
+
+// estimateDelivery estimates the delivery of the order from the deliveries of its provider over the past
+// ESTIMATE_HISTORY_DAYS days and saves it on the order. Failures are only logged, the order keeps its last estimate.
+func (s *LogisticService) estimateDelivery(ctx context.Context, order *domain.Order) {
+	samples, err := s.repo.GetDeliverySamples(ctx, order.ProviderID, time.Now().AddDate(0, 0, -configs.EstimateHistoryDays),
+		configs.EstimateMaxSamples)
+	if err != nil {
+		slog.WarnContext(ctx, "estimating delivery failed", "provider_id", order.ProviderID, "error", err.Err)
+		return
+	}
+	estimate := domain.EstimateDelivery(order, samples, time.Now())
+	if err = s.repo.UpdateOrderEstimate(ctx, order.ID, &estimate); err != nil {
+		slog.WarnContext(ctx, "saving delivery estimate failed", "error", err.Err)
+		return
+	}
+	order.EstimatedDelivery = estimate
+	slog.DebugContext(ctx, "delivery estimated", "date", estimate.Date, "samples", estimate.Samples)
+}
+
+// estimatesAsOfToday moves the past dates of the estimates of the orders to today, every version of the orders
+// returned by the service shows the same estimate.
+func estimatesAsOfToday(orders ...*domain.Order) {
+	now := time.Now()
+	for _, order := range orders {
+		order.EstimatedDelivery = order.EstimatedDelivery.AsOf(now)
+	}
+}
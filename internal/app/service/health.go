@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queryFailed is the detail of a check whose query failed, the error itself is only logged.
+const queryFailed = "query failed"
+
+// providersHealthCache keeps the last providers check, so that frequent readiness probes
+// do not send a request to every provider each time.
+type providersHealthCache struct {
+	mu        sync.Mutex
+	check     *domain.HealthCheck
+	checkedAt time.Time
+}
+
+// Liveness only reports that the process is able to serve requests.
+func (s *LogisticService) Liveness(ctx context.Context) (*domain.HealthReport, *errors.AppError) {
+	return domain.NewHealthReport(nil), nil
+}
+
+// Readiness checks the dependencies of the service. Only a failing database or
+// schema makes the service not ready, the other checks can degrade it.
+func (s *LogisticService) Readiness(ctx context.Context) (*domain.HealthReport, *errors.AppError) {
+	ctx, cancel := context.WithTimeout(ctx, configs.HealthCheckTimeout)
+	defer cancel()
+
+	checks := map[string]*domain.HealthCheck{
+		"database": s.checkDatabase(ctx),
+	}
+	if checks["database"].Status == domain.GetHealthStatus().Down {
+		return domain.NewHealthReport(checks), nil
+	}
+	checks["migrations"] = s.checkMigrations(ctx)
+	checks[orderTaskJobName] = s.checkOrderTask(ctx)
+	checks["providers"] = s.checkProviders(ctx)
+	return domain.NewHealthReport(checks), nil
+}
+
+func (s *LogisticService) checkDatabase(ctx context.Context) *domain.HealthCheck {
+	start := time.Now()
+	if err := s.repo.Ping(ctx); err != nil {
+		slog.ErrorContext(ctx, "database check failed", "error", err.Err)
+		return &domain.HealthCheck{Status: domain.GetHealthStatus().Down, Detail: "unreachable"}
+	}
+	return &domain.HealthCheck{
+		Status: domain.GetHealthStatus().Up,
+		Detail: fmt.Sprintf("ping took %s", time.Since(start).Round(time.Microsecond)),
+	}
+}
+
+func (s *LogisticService) checkMigrations(ctx context.Context) *domain.HealthCheck {
+	version, err := s.repo.GetMigrationVersion(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "migrations check failed", "error", err.Err)
+		return &domain.HealthCheck{Status: domain.GetHealthStatus().Down, Detail: queryFailed}
+	}
+	if version.Applied < version.Expected {
+		return &domain.HealthCheck{
+			Status: domain.GetHealthStatus().Down,
+			Detail: fmt.Sprintf("schema version %d is behind expected version %d", version.Applied, version.Expected),
+		}
+	}
+	if version.Applied > version.Expected {
+		return &domain.HealthCheck{
+			Status: domain.GetHealthStatus().Degraded,
+			Detail: fmt.Sprintf("schema version %d is ahead of expected version %d", version.Applied, version.Expected),
+		}
+	}
+	return &domain.HealthCheck{
+		Status: domain.GetHealthStatus().Up,
+		Detail: fmt.Sprintf("schema version %d", version.Applied),
+	}
+}
+
+func (s *LogisticService) checkOrderTask(ctx context.Context) *domain.HealthCheck {
+	task, err := s.repo.GetPeriodicTask(ctx, orderTaskJobName)
+	if err != nil && err.Code != http.StatusNotFound {
+		slog.ErrorContext(ctx, "order task check failed", "error", err.Err)
+		return &domain.HealthCheck{Status: domain.GetHealthStatus().Down, Detail: queryFailed}
+	}
+	if task == nil || task.LastSuccessTime == nil {
+		return &domain.HealthCheck{Status: domain.GetHealthStatus().Degraded, Detail: "task has never run successfully"}
+	}
+
+	interval := time.Duration(task.IntervalInMinute) * time.Minute
+	if interval == 0 {
+		interval = configs.OrderUpdatePeriod
+	}
+	age := time.Since(*task.LastSuccessTime).Round(time.Second)
+	// a run may take a while, so the task is stale only after missing a whole interval
+	if age > 2*interval {
+		return &domain.HealthCheck{
+			Status: domain.GetHealthStatus().Degraded,
+			Detail: fmt.Sprintf("last successful run was %s ago, interval is %s", age, interval),
+		}
+	}
+	return &domain.HealthCheck{
+		Status: domain.GetHealthStatus().Up,
+		Detail: fmt.Sprintf("last successful run was %s ago, interval is %s", age, interval),
+	}
+}
+
+func (s *LogisticService) checkProviders(ctx context.Context) *domain.HealthCheck {
+	cache := s.providersHealth
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.check != nil && time.Since(cache.checkedAt) < configs.HealthProviderCheckInterval {
+		return cache.check
+	}
+
+	all, err := s.repo.GetAllProviders(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "providers check failed", "error", err.Err)
+		return &domain.HealthCheck{Status: domain.GetHealthStatus().Down, Detail: queryFailed}
+	}
+	// providers taking no new orders do not degrade the service
+	var providers []*domain.Provider
+	for _, provider := range all {
+		if provider.TakesOrders() {
+			providers = append(providers, provider)
+		}
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		unreachable []string
+	)
+	for _, provider := range providers {
+		wg.Add(1)
+		go func(p *domain.Provider) {
+			defer wg.Done()
+			if !s.providerReachable(ctx, p) {
+				mu.Lock()
+				unreachable = append(unreachable, p.Name)
+				mu.Unlock()
+			}
+		}(provider)
+	}
+	wg.Wait()
+
+	check := &domain.HealthCheck{
+		Status: domain.GetHealthStatus().Up,
+		Detail: fmt.Sprintf("%d/%d providers reachable", len(providers)-len(unreachable), len(providers)),
+	}
+	if len(unreachable) > 0 {
+		// the names of the providers are only logged, the readiness check is not authenticated
+		slog.WarnContext(ctx, "providers unreachable", "providers", strings.Join(unreachable, ", "))
+		check.Status = domain.GetHealthStatus().Degraded
+	}
+	cache.check = check
+	cache.checkedAt = time.Now()
+	return check
+}
+
+func (s *LogisticService) providerReachable(ctx context.Context, provider *domain.Provider) bool {
+	return s.providers.Ping(ctx, provider) == nil
+}
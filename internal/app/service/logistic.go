@@ -4,29 +4,41 @@ import (
 	"context"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"log/slog"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/app/ports"
 	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/logger"
+	"net/http"
 	"time"
 )
 
 type LogisticService struct {
-	repo ports.Repo
-}
+	repo       ports.Repo
+	providers  ports.ProviderGateway
+	strategies map[string]selectionStrategy
 
-func NewLogisticService(repo ports.Repo) *LogisticService {
-	return &LogisticService{repo: repo}
+	providersHealth *providersHealthCache
 }
 
-func (s *LogisticService) HealthCheck(ctx context.Context) (any, *errors.AppError) {
-	return map[string]bool{
-		"db_healthy": s.repo.Ready(),
-	}, nil
+func NewLogisticService(repo ports.Repo, providers ports.ProviderGateway) *LogisticService {
+	s := &LogisticService{
+		repo:      repo,
+		providers: providers,
+
+		providersHealth: &providersHealthCache{},
+	}
+	s.strategies = newSelectionStrategies(s)
+	return s
 }
 
-func (s *LogisticService) GetProviders(ctx context.Context) ([]*domain.Provider, *errors.AppError) {
-	return s.repo.GetAllProviders(ctx)
+func (s *LogisticService) GetProviders(ctx context.Context, request *domain.ProviderListRequest) (*domain.Page[*domain.Provider], *errors.AppError) {
+	providers, total, err := s.repo.ListProviders(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPage(providers, request.Pagination(), total), nil
 }
 
 func (s *LogisticService) GetProvidersMeanDelTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError) {
@@ -34,14 +46,43 @@ func (s *LogisticService) GetProvidersMeanDelTime(ctx context.Context) ([]*domai
 }
 
 func (s *LogisticService) CreateProvider(ctx context.Context, request *domain.ProviderCreateRequest) (*domain.Provider, *errors.AppError) {
-	return s.repo.CreateProvider(ctx, &request.Name, &request.Url)
+	provider, err := s.repo.CreateProvider(ctx, &request.Name, &request.Url, request.QuoteUrl)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "provider created", "provider_id", provider.ID)
+	go s.verifyProvider(context.WithoutCancel(ctx), provider)
+	return provider, nil
+}
+
+func (s *LogisticService) GetProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError) {
+	return s.repo.GetProvider(ctx, request.ProviderID)
+}
+
+// UpdateProvider changes the provider, deactivated providers stop taking new orders
+// while their ongoing orders are still tracked. A new url is verified again in the background.
+func (s *LogisticService) UpdateProvider(ctx context.Context, request *domain.ProviderUpdateRequest) (*domain.Provider, *errors.AppError) {
+	provider, err := s.repo.UpdateProvider(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "provider updated", "provider_id", provider.ID, "active", provider.Active)
+	if request.Url != nil && provider.VerificationStatus == domain.GetProviderVerificationStatus().Pending {
+		go s.verifyProvider(context.WithoutCancel(ctx), provider)
+	}
+	return provider, nil
 }
 
 func (s *LogisticService) CreateCustomer(ctx context.Context, request *domain.CustomerCreateRequest) (*domain.Customer, *errors.AppError) {
-	return s.repo.CreateCustomer(ctx, request.Name, &request.PhoneNumber, &request.Address, &request.PostalCode)
+	customer, err := s.repo.CreateCustomer(ctx, request.Name, &request.PhoneNumber, &request.Address, &request.PostalCode)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "customer created", "customer_id", customer.ID)
+	return customer, nil
 }
 
-func (s *LogisticService) GetCustomerToken(ctx context.Context, request *domain.CustomerTokenRequest) (any, *errors.AppError) {
+func (s *LogisticService) GetCustomerToken(ctx context.Context, request *domain.CustomerTokenRequest) (*domain.CustomerToken, *errors.AppError) {
 	var byteSecKey = []byte(configs.SecretKey)
 
 	claims := jwt.MapClaims{
@@ -55,7 +96,7 @@ func (s *LogisticService) GetCustomerToken(ctx context.Context, request *domain.
 	if e != nil {
 		return nil, errors.InternalServerError(e)
 	}
-	return map[string]string{"token": signed}, nil
+	return &domain.CustomerToken{Token: signed}, nil
 }
 
 func (s *LogisticService) CreateOrder(ctx context.Context, request *domain.OrderCreateRequest) (*domain.Order, *errors.AppError) {
@@ -68,7 +109,61 @@ func (s *LogisticService) CreateOrder(ctx context.Context, request *domain.Order
 		return nil, err
 	}
 
-	return s.repo.CreateOrder(ctx, customer.ID, request.ReceiverID, request.ProviderID, request.Product)
+	if err = s.checkReceiver(ctx, request.ReceiverID); err != nil {
+		return nil, err
+	}
+	if err = s.checkAddressOwner(ctx, "pickup_address_id", request.PickupAddressID, customer.ID); err != nil {
+		return nil, err
+	}
+	if err = s.checkAddressOwner(ctx, "dropoff_address_id", request.DropoffAddressID, request.ReceiverID); err != nil {
+		return nil, err
+	}
+
+	selection := &providerSelection{providerID: request.ProviderID}
+	if request.ProviderID == 0 {
+		if selection, err = s.selectProvider(ctx, request, customer.ID); err != nil {
+			return nil, err
+		}
+		request.ProviderID = selection.providerID
+	} else if request.Strategy != nil {
+		return nil, errors.ValidationError(map[string]string{"strategy": "is only used when provider_id is left out"})
+	}
+	if err = s.checkProvider(ctx, request.ProviderID); err != nil {
+		return nil, err
+	}
+	quote, price, err := s.checkQuote(ctx, request, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	parcels, err := orderParcels(request, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.Order{
+		ProviderID:       request.ProviderID,
+		SenderID:         customer.ID,
+		ReceiverID:       request.ReceiverID,
+		Product:          request.Product,
+		PickupAddressID:  request.PickupAddressID,
+		DropoffAddressID: request.DropoffAddressID,
+		QuoteID:          request.QuoteID,
+		Price:            price,
+		SelectionScores:  selection.scores,
+	}
+	if selection.strategy != "" {
+		order.SelectionStrategy = &selection.strategy
+	}
+	order.SetParcels(parcels)
+	order, err = s.repo.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	ctx = logger.WithOrderID(ctx, order.ID)
+	slog.InfoContext(ctx, "order created", "provider_id", order.ProviderID, "receiver_id", order.ReceiverID)
+	s.estimateDelivery(ctx, order)
+	go s.bookShipment(context.WithoutCancel(ctx), order)
+	return order, nil
 }
 
 func (s *LogisticService) GetOrder(ctx context.Context, request *domain.OrderGetRequest) (*domain.Order, *errors.AppError) {
@@ -76,5 +171,80 @@ func (s *LogisticService) GetOrder(ctx context.Context, request *domain.OrderGet
 	if !ok {
 		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
 	}
-	return s.repo.GetOrderWithForeignObjects(ctx, request.OrderID, userID)
+	ctx = logger.WithOrderID(ctx, request.OrderID)
+	order, err := s.repo.GetOrderWithForeignObjects(ctx, request.OrderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	estimatesAsOfToday(order)
+	return order, nil
+}
+
+// checkProvider reports a provider of the order which does not exist, and rejects providers which
+// are inactive or not verified yet.
+func (s *LogisticService) checkProvider(ctx context.Context, providerID uint) *errors.AppError {
+	provider, err := s.repo.GetProvider(ctx, providerID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return errors.ValidationError(map[string]string{"provider_id": "does not exist"})
+		}
+		return err
+	}
+	if !provider.Active {
+		return errors.UnprocessableEntity(errors.CodeProviderInactive, "the provider does not take new orders")
+	}
+	if !provider.TakesOrders() {
+		return errors.UnprocessableEntity(errors.CodeProviderUnverified, "the provider is pending verification")
+	}
+	return nil
+}
+
+// orderParcels normalizes the parcels of the request, or takes the parcel of the quote when both parcel and parcels
+// are left out. Parcels heavier in total than the parcel of the quote are not covered by its price.
+func orderParcels(request *domain.OrderCreateRequest, quote *domain.Quote) ([]domain.Parcel, *errors.AppError) {
+	var parcels []domain.Parcel
+	invalid := make(map[string]string)
+	switch {
+	case request.Parcel != nil && len(request.Parcels) > 0:
+		return nil, errors.ValidationError(map[string]string{"parcels": "cannot be sent along with parcel"})
+	case request.Parcel != nil:
+		parcel := *request.Parcel
+		for field, msg := range parcel.Normalize("parcel") {
+			invalid[field] = msg
+		}
+		parcels = append(parcels, parcel)
+	case len(request.Parcels) > 0:
+		for i, parcel := range request.Parcels {
+			if parcel == nil {
+				invalid[fmt.Sprintf("parcels[%d]", i)] = "is required"
+				continue
+			}
+			for field, msg := range parcel.Normalize(fmt.Sprintf("parcels[%d]", i)) {
+				invalid[field] = msg
+			}
+			parcels = append(parcels, *parcel)
+		}
+	case quote != nil:
+		return []domain.Parcel{quote.Parcel}, nil
+	default:
+		return nil, nil
+	}
+	if len(invalid) > 0 {
+		return nil, errors.ValidationError(invalid)
+	}
+	if quote != nil && domain.TotalParcel(parcels).ChargeableWeight > quote.Parcel.ChargeableWeight {
+		return nil, errors.UnprocessableEntity(errors.CodeQuoteMismatch, "the parcels are heavier than the parcel of the quote")
+	}
+	return parcels, nil
+}
+
+// checkReceiver reports a receiver of the order which does not exist.
+func (s *LogisticService) checkReceiver(ctx context.Context, receiverID uint) *errors.AppError {
+	if _, err := s.repo.GetCustomer(ctx, receiverID); err != nil {
+		if err.Code == http.StatusNotFound {
+			return errors.ValidationError(map[string]string{"receiver_id": "does not exist"})
+		}
+		return err
+	}
+	return nil
 }
@@ -2,14 +2,18 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"io"
-	"log"
+	stdErrors "errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
 	"logistic-app/internal/app/domain"
 	"logistic-app/internal/common/configs"
 	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/logger"
+	"logistic-app/internal/common/telemetry"
 	"math/rand"
-	"net/http"
+	"os"
 	"sync"
 	"time"
 )
@@ -23,7 +27,8 @@ func (s *LogisticService) ScheduleUpdateOrderStatus() {
 	task, err := s.repo.GetOrCreatePeriodicTask(
 		ctx, orderTaskJobName, orderTaskIntervalInMinutes)
 	if err != nil {
-		log.Fatal(err.Err)
+		slog.ErrorContext(ctx, "could not get periodic task", "job", orderTaskJobName, "error", err.Err)
+		os.Exit(1)
 	}
 
 	var lastRun time.Time
@@ -46,7 +51,7 @@ func (s *LogisticService) ScheduleUpdateOrderStatus() {
 }
 
 func (s *LogisticService) updateOrderWithRetries(retries int) {
-	ctx := context.Background()
+	ctx, span := telemetry.Tracer().Start(context.Background(), orderTaskJobName)
 	var (
 		failed bool
 		orders []*domain.Order
@@ -55,9 +60,19 @@ func (s *LogisticService) updateOrderWithRetries(retries int) {
 		eStr   string
 	)
 	defer func() {
-		log.Printf("Updated orders -> failed: %v, errors: %s", failed, eStr)
+		if failed {
+			slog.ErrorContext(ctx, "updating orders failed", "job", orderTaskJobName, "error", eStr)
+		} else {
+			slog.InfoContext(ctx, "updated orders", "job", orderTaskJobName, "count", len(orders))
+		}
 		s.repo.CreateOrUpdatePeriodicTask(ctx, orderTaskJobName, orderTaskIntervalInMinutes, failed, &eStr)
+		if failed {
+			telemetry.End(span, fmt.Errorf("%s", eStr))
+		} else {
+			span.End()
+		}
 	}()
+	s.retryBookings(ctx)
 	orders, err = s.repo.GetOngoingOrders(ctx)
 	if err != nil {
 		failed = true
@@ -66,7 +81,7 @@ func (s *LogisticService) updateOrderWithRetries(retries int) {
 	}
 
 	for i := 0; i < retries; i++ {
-		orders, es = s.updateOrdersStatusTask(orders)
+		orders, es = s.updateOrdersStatusTask(ctx, orders)
 		if len(orders) == 0 {
 			break
 		}
@@ -79,8 +94,7 @@ func (s *LogisticService) updateOrderWithRetries(retries int) {
 	}
 }
 
-func (s *LogisticService) updateOrdersStatusTask(orders []*domain.Order) ([]*domain.Order, []error) {
-	ctx := context.Background()
+func (s *LogisticService) updateOrdersStatusTask(ctx context.Context, orders []*domain.Order) ([]*domain.Order, []error) {
 	sem := make(chan struct{}, configs.PeriodicTaskMaxConcurrency)
 	var wg sync.WaitGroup
 	var failedOrders []*domain.Order
@@ -96,8 +110,13 @@ func (s *LogisticService) updateOrdersStatusTask(orders []*domain.Order) ([]*dom
 			c, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
 
+			c = logger.WithOrderID(c, o.ID)
+			c, span := telemetry.Tracer().Start(c, "orderUpdateWorker",
+				trace.WithAttributes(attribute.Int64("order.id", int64(o.ID))))
 			e := s.orderUpdateWorker(c, o)
+			telemetry.End(span, e)
 			if e != nil {
+				slog.WarnContext(c, "updating order status failed", "error", e)
 				mu.Lock()
 				failedOrders = append(failedOrders, o)
 				errs = append(errs, e)
@@ -111,43 +130,110 @@ func (s *LogisticService) updateOrdersStatusTask(orders []*domain.Order) ([]*dom
 	return failedOrders, errs
 }
 
-func (s *LogisticService) orderUpdateWorker(ctx context.Context, order *domain.Order) error {
+// bookShipment books the shipment of the order with its provider, with the contacts and the addresses kept on
+// the order. The booking is saved on the order, failed bookings stay pending for retryBookings until
+// BOOKING_MAX_ATTEMPTS attempts failed. Failures are only logged, as the order is already created.
+func (s *LogisticService) bookShipment(ctx context.Context, order *domain.Order) {
+	ctx, cancel := context.WithTimeout(ctx, configs.ProviderRequestTimeout)
+	defer cancel()
+	ctx, span := telemetry.Tracer().Start(ctx, "bookShipment",
+		trace.WithAttributes(attribute.Int64("order.id", int64(order.ID))))
+
 	provider, err := s.repo.GetProvider(ctx, order.ProviderID)
-	if provider == nil {
-		return err.Err
+	if err == nil {
+		err = s.providers.BookShipment(ctx, provider, domain.NewShipment(order))
 	}
-
-	resp, e := http.Get(provider.Url)
-	if e != nil {
-		return e
+	attempts, status := order.BookingAttempts+1, domain.GetOrderBookingStatus().Booked
+	if err != nil {
+		status = domain.GetOrderBookingStatus().Pending
+		if attempts >= uint(configs.BookingMaxAttempts) {
+			status = domain.GetOrderBookingStatus().Failed
+		}
 	}
-	defer resp.Body.Close()
-
-	dataBytes, e := io.ReadAll(resp.Body)
-	if e != nil {
-		return e
+	// the booking is saved even when the provider took all of the timeout
+	if e := s.repo.UpdateOrderBooking(context.WithoutCancel(ctx), order.ID, status, attempts); e != nil {
+		slog.ErrorContext(ctx, "could not save the booking", "booking_status", status, "error", e.Err)
 	}
 
-	var data *domain.ProviderUrlResponse
-	if e = json.Unmarshal(dataBytes, &data); e != nil {
-		return e
+	if err != nil {
+		slog.WarnContext(ctx, "booking shipment failed", "provider_id", order.ProviderID, "attempts", attempts,
+			"booking_status", status, "error", err.Err)
+		telemetry.End(span, err.Err)
+		return
 	}
+	slog.InfoContext(ctx, "shipment booked", "provider_id", order.ProviderID)
+	span.End()
+}
 
-	// simulating real scenario
-	source := domain.ConvertOrderStatusToNumber(order.Status)
-	choice := rand.Intn(2)
-	log.Println(source, choice)
-	if source == 0 && choice == 0 {
-		return nil
+// retryBookings books the shipments of the orders whose booking failed, as long as they are not picked up. Orders
+// created within the timeout of the providers are left to the booking started when they were created.
+func (s *LogisticService) retryBookings(ctx context.Context) {
+	orders, err := s.repo.GetUnbookedOrders(ctx, time.Now().Add(-2*configs.ProviderRequestTimeout))
+	if err != nil {
+		slog.ErrorContext(ctx, "could not get unbooked orders", "job", orderTaskJobName, "error", err.Err)
+		return
 	}
+	for _, order := range orders {
+		s.bookShipment(logger.WithOrderID(ctx, order.ID), order)
+	}
+}
 
-	newStatus := domain.ConvertOrderStatus(data.Data[source-1+choice].StatusNumber)
-	if newStatus == domain.GetOrderStatus().PickedUp {
-		s.NotifyReceiver(order)
+// orderUpdateWorker reads the status of each parcel of the order which is not delivered yet from its provider,
+// the status of the order is derived from the statuses of its parcels and its delivery is estimated again
+// when the status changed. A parcel which could not be updated does not hold back the others, the errors of
+// the parcels are returned together once the receiver is notified of the pickup.
+func (s *LogisticService) orderUpdateWorker(ctx context.Context, order *domain.Order) error {
+	status := order.Status
+	provider, err := s.repo.GetProvider(ctx, order.ProviderID)
+	if provider == nil {
+		return err.Err
 	}
-	_, err = s.repo.UpdateOrderStatus(ctx, order.ID, newStatus)
+	parcels, err := s.repo.GetOrderParcels(ctx, order.ID)
 	if err != nil {
 		return err.Err
 	}
-	return nil
+
+	pickedUp := false
+	var errs []error
+	for _, parcel := range parcels {
+		if parcel.Status == domain.GetOrderStatus().Delivered {
+			continue
+		}
+		data, err := s.providers.GetStatuses(ctx, provider, parcel.TrackingNumber)
+		if err != nil {
+			slog.WarnContext(ctx, "reading parcel status failed", "tracking_number", parcel.TrackingNumber, "error", err.Err)
+			errs = append(errs, fmt.Errorf("parcel %s: %w", parcel.TrackingNumber, err.Err))
+			continue
+		}
+
+		// simulating real scenario
+		source := domain.ConvertOrderStatusToNumber(parcel.Status)
+		choice := rand.Intn(2)
+		slog.DebugContext(ctx, "simulated provider status", "tracking_number", parcel.TrackingNumber, "source", source, "choice", choice)
+		if source == 0 && choice == 0 {
+			continue
+		}
+
+		newStatus := domain.ConvertOrderStatus(data.Data[source-1+choice].StatusNumber)
+		if newStatus == parcel.Status {
+			continue
+		}
+		updated, err := s.repo.UpdateParcelStatus(ctx, parcel.ID, newStatus)
+		if err != nil {
+			slog.WarnContext(ctx, "updating parcel status failed", "tracking_number", parcel.TrackingNumber, "error", err.Err)
+			errs = append(errs, fmt.Errorf("parcel %s: %w", parcel.TrackingNumber, err.Err))
+			continue
+		}
+		pickedUp = pickedUp || newStatus == domain.GetOrderStatus().PickedUp
+		order = updated
+		slog.InfoContext(ctx, "updated parcel status", "tracking_number", parcel.TrackingNumber, "status", newStatus)
+	}
+	if pickedUp {
+		s.NotifyReceiver(ctx, order)
+	}
+	if order.Status != status {
+		s.estimateDelivery(ctx, order)
+	}
+	slog.InfoContext(ctx, "updated order status", "status", order.Status)
+	return stdErrors.Join(errs...)
 }
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/errors"
+	"testing"
+	"time"
+)
+
+// statusRepo holds the parcels of an order, failing the updates of the parcels in failUpdates.
+type statusRepo struct {
+	ports.Repo
+	parcels     []*domain.OrderParcel
+	failUpdates map[uint]bool
+	updated     map[uint]string
+}
+
+func (r *statusRepo) GetProvider(ctx context.Context, providerID uint) (*domain.Provider, *errors.AppError) {
+	return &domain.Provider{ID: providerID}, nil
+}
+
+func (r *statusRepo) GetOrderParcels(ctx context.Context, orderID uint) ([]*domain.OrderParcel, *errors.AppError) {
+	return r.parcels, nil
+}
+
+func (r *statusRepo) UpdateParcelStatus(ctx context.Context, parcelID uint, status string) (*domain.Order, *errors.AppError) {
+	if r.failUpdates[parcelID] {
+		return nil, errors.InternalServerError(stdErrors.New("update failed"))
+	}
+	r.updated[parcelID] = status
+	return &domain.Order{ID: 1, Status: status, NotifiedReceiver: true}, nil
+}
+
+func (r *statusRepo) GetDeliverySamples(ctx context.Context, providerID uint, since time.Time, limit int) ([]*domain.DeliverySample, *errors.AppError) {
+	return nil, nil
+}
+
+func (r *statusRepo) UpdateOrderEstimate(ctx context.Context, orderID uint, estimate *domain.DeliveryEstimate) *errors.AppError {
+	return nil
+}
+
+// statusGateway answers every parcel but the ones in fail with the statuses.
+type statusGateway struct {
+	ports.ProviderGateway
+	fail map[string]bool
+}
+
+func (g *statusGateway) GetStatuses(ctx context.Context, provider *domain.Provider, trackingNumber string) (*domain.ProviderUrlResponse, *errors.AppError) {
+	if g.fail[trackingNumber] {
+		return nil, errors.InternalServerError(stdErrors.New("provider unavailable"))
+	}
+	return &domain.ProviderUrlResponse{Data: []*domain.OrderStatusInResponse{
+		{StatusNumber: "2"}, {StatusNumber: "2"}, {StatusNumber: "3"},
+	}}, nil
+}
+
+func TestOrderUpdateWorker(t *testing.T) {
+	pickedUp := domain.GetOrderStatus().PickedUp
+	repo := &statusRepo{
+		parcels: []*domain.OrderParcel{
+			{ID: 1, TrackingNumber: "PARCEL000001", Status: pickedUp},
+			{ID: 2, TrackingNumber: "PARCEL000002", Status: pickedUp},
+			{ID: 3, TrackingNumber: "PARCEL000003", Status: pickedUp},
+		},
+		failUpdates: map[uint]bool{3: true},
+		updated:     make(map[uint]string),
+	}
+	s := NewLogisticService(repo, &statusGateway{fail: map[string]bool{"PARCEL000001": true}})
+
+	err := s.orderUpdateWorker(context.Background(), &domain.Order{ID: 1, ProviderID: 1, Status: pickedUp})
+	assert.ErrorContains(t, err, "parcel PARCEL000001")
+	assert.ErrorContains(t, err, "parcel PARCEL000003")
+	assert.Equal(t, map[uint]string{2: domain.GetOrderStatus().InProgress}, repo.updated)
+}
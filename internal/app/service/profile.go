@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"math/big"
+	"net/http"
+)
+
+func (s *LogisticService) GetProfile(ctx context.Context) (*domain.Customer, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	customer, err := s.repo.GetCustomer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPendingPhoneNumber(ctx, customer)
+}
+
+// UpdateProfile applies the changes of the name and the address right away. A new phone number
+// is kept pending and a verification code is sent to it, it is applied by VerifyPhoneNumber.
+// New phone numbers are refused while the verification codes cannot be delivered.
+func (s *LogisticService) UpdateProfile(ctx context.Context, request *domain.CustomerUpdateRequest) (*domain.Customer, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	if request.PhoneNumber != nil && !verificationCodesDelivered {
+		customer, err := s.repo.GetCustomer(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if *request.PhoneNumber != customer.PhoneNumber {
+			return nil, phoneChangeDisabled()
+		}
+	}
+
+	customer, err := s.repo.UpdateCustomer(ctx, userID, &domain.CustomerUpdate{
+		Name:       request.Name,
+		Address:    request.Address,
+		PostalCode: request.PostalCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "customer updated", "customer_id", customer.ID)
+
+	if request.PhoneNumber != nil && *request.PhoneNumber != customer.PhoneNumber {
+		code, e := verificationCode()
+		if e != nil {
+			return nil, errors.InternalServerError(e)
+		}
+		verification, err := s.repo.CreatePhoneVerification(ctx, customer.ID, *request.PhoneNumber, hashVerificationCode(code), configs.PhoneVerificationTTL)
+		if err != nil {
+			return nil, err
+		}
+		s.SendVerificationCode(ctx, verification.PhoneNumber, code)
+	}
+	return s.withPendingPhoneNumber(ctx, customer)
+}
+
+// VerifyPhoneNumber applies the pending phone number of the customer when the code matches.
+// The change is dropped once it expires or too many wrong codes were sent.
+func (s *LogisticService) VerifyPhoneNumber(ctx context.Context, request *domain.PhoneVerifyRequest) (*domain.Customer, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	if !verificationCodesDelivered {
+		return nil, phoneChangeDisabled()
+	}
+
+	verification, err := s.repo.GetPhoneVerification(ctx, userID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, errors.UnprocessableEntity(errors.CodeVerificationExpired, "there is no pending phone number change")
+		}
+		return nil, err
+	}
+	if verification.Expired() || verification.Attempts >= configs.PhoneVerificationMaxAttempts {
+		if err = s.repo.DeletePhoneVerification(ctx, verification.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.UnprocessableEntity(errors.CodeVerificationExpired, "the verification code expired, change the phone number again")
+	}
+	if !hmac.Equal([]byte(verification.CodeHash), []byte(hashVerificationCode(request.Code))) {
+		if err = s.repo.IncrementPhoneVerificationAttempts(ctx, verification.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.UnprocessableEntity(errors.CodeVerificationInvalid, "the verification code is not valid")
+	}
+
+	customer, err := s.repo.UpdateCustomer(ctx, userID, &domain.CustomerUpdate{PhoneNumber: &verification.PhoneNumber})
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repo.DeletePhoneVerification(ctx, verification.ID); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "customer phone number verified", "customer_id", customer.ID)
+	return customer, nil
+}
+
+func phoneChangeDisabled() *errors.AppError {
+	return errors.UnprocessableEntity(errors.CodePhoneChangeDisabled, "phone numbers cannot be changed until verification codes can be sent")
+}
+
+func (s *LogisticService) withPendingPhoneNumber(ctx context.Context, customer *domain.Customer) (*domain.Customer, *errors.AppError) {
+	verification, err := s.repo.GetPhoneVerification(ctx, customer.ID)
+	if err != nil && err.Code != http.StatusNotFound {
+		return nil, err
+	}
+	if verification != nil && !verification.Expired() && verification.Attempts < configs.PhoneVerificationMaxAttempts {
+		customer.PendingPhoneNumber = &verification.PhoneNumber
+	}
+	return customer, nil
+}
+
+// verificationCode returns a random code of 6 digits.
+func verificationCode() (string, error) {
+	n, e := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if e != nil {
+		return "", e
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashVerificationCode(code string) string {
+	mac := hmac.New(sha256.New, []byte(configs.SecretKey))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"testing"
+)
+
+// profileRepo holds a single customer and counts its updates.
+type profileRepo struct {
+	ports.Repo
+	customer *domain.Customer
+	updates  int
+}
+
+func (r *profileRepo) GetCustomer(ctx context.Context, userID uint) (*domain.Customer, *errors.AppError) {
+	return r.customer, nil
+}
+
+func (r *profileRepo) UpdateCustomer(ctx context.Context, customerID uint, update *domain.CustomerUpdate) (*domain.Customer, *errors.AppError) {
+	r.updates++
+	return r.customer, nil
+}
+
+func (r *profileRepo) GetPhoneVerification(ctx context.Context, customerID uint) (*domain.PhoneVerification, *errors.AppError) {
+	return nil, errors.NotFoundError(nil)
+}
+
+func TestUpdateProfile_PhoneChangeDisabled(t *testing.T) {
+	repo := &profileRepo{customer: &domain.Customer{ID: 1, PhoneNumber: "+989121234567"}}
+	s := NewLogisticService(repo, nil)
+	ctx := context.WithValue(context.Background(), configs.UserIDKey, uint(1))
+	address := "somewhere else"
+
+	t.Run("New Phone Number", func(t *testing.T) {
+		phone := "+989127654321"
+		_, err := s.UpdateProfile(ctx, &domain.CustomerUpdateRequest{PhoneNumber: &phone, Address: &address})
+		assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+		assert.Equal(t, errors.CodePhoneChangeDisabled, err.ApiErr.Code)
+		assert.Zero(t, repo.updates)
+
+		_, err = s.VerifyPhoneNumber(ctx, &domain.PhoneVerifyRequest{Code: "123456"})
+		assert.Equal(t, errors.CodePhoneChangeDisabled, err.ApiErr.Code)
+	})
+
+	t.Run("Same Phone Number", func(t *testing.T) {
+		phone := repo.customer.PhoneNumber
+		_, err := s.UpdateProfile(ctx, &domain.CustomerUpdateRequest{PhoneNumber: &phone, Address: &address})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, repo.updates)
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func (s *LogisticService) ListTariffs(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.Tariff, *errors.AppError) {
+	return s.repo.ListTariffs(ctx, request.ProviderID)
+}
+
+// UpdateTariffs replaces the tariffs of the provider, an empty list leaves the provider to quote through its quote url.
+func (s *LogisticService) UpdateTariffs(ctx context.Context, request *domain.TariffsUpdateRequest) ([]*domain.Tariff, *errors.AppError) {
+	tariffs := make([]*domain.Tariff, 0, len(request.Tariffs))
+	for _, input := range request.Tariffs {
+		tariffs = append(tariffs, &domain.Tariff{
+			MaxWeight:    input.MaxWeight,
+			Price:        input.Price,
+			DeliveryDays: input.DeliveryDays,
+		})
+	}
+
+	tariffs, err := s.repo.ReplaceTariffs(ctx, request.ProviderID, tariffs)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "tariffs updated", "provider_id", request.ProviderID, "tariffs", len(tariffs))
+	return tariffs, nil
+}
+
+// CreateQuote prices the parcel with every provider taking orders between the postal codes, by its tariffs
+// or through its quote url, and ranks their options. Providers which cannot price the parcel are left out.
+func (s *LogisticService) CreateQuote(ctx context.Context, request *domain.QuoteCreateRequest) (*domain.Quote, *errors.AppError) {
+	userID, ok := ctx.Value(configs.UserIDKey).(uint)
+	if !ok {
+		return nil, errors.NotFoundError(fmt.Errorf("user uuid not found in context"))
+	}
+	if invalid := request.Parcel.Normalize("parcel"); invalid != nil {
+		return nil, errors.ValidationError(invalid)
+	}
+	providers, err := s.repo.GetQuotableProviders(ctx, request.PickupPostalCode, request.DropoffPostalCode)
+	if err != nil {
+		return nil, err
+	}
+	meanTimes, err := s.repo.GetProvidersMeanDeliveryTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meanTimeOf := make(map[uint]float32, len(meanTimes))
+	for _, meanTime := range meanTimes {
+		meanTimeOf[meanTime.ProviderID] = meanTime.MeanDeliveryTimeInDays
+	}
+
+	options := make([]*domain.QuoteOption, len(providers))
+	wg := sync.WaitGroup{}
+	for i, provider := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			option, err := s.quoteOption(ctx, provider, request)
+			if err != nil {
+				slog.WarnContext(ctx, "provider could not be quoted", "provider_id", provider.ID, "error", err.Err)
+				return
+			}
+			if meanTime, ok := meanTimeOf[provider.ID]; ok {
+				option.MeanDeliveryTimeInDays = &meanTime
+			}
+			options[i] = option
+		}()
+	}
+	wg.Wait()
+
+	quote := &domain.Quote{
+		CustomerID:        userID,
+		PickupPostalCode:  request.PickupPostalCode,
+		DropoffPostalCode: request.DropoffPostalCode,
+		Parcel:            request.Parcel,
+		Options:           make([]*domain.QuoteOption, 0, len(options)),
+		ExpiresAt:         time.Now().Add(configs.QuoteTTL),
+	}
+	for _, option := range options {
+		if option != nil {
+			quote.Options = append(quote.Options, option)
+		}
+	}
+	domain.RankQuoteOptions(quote.Options)
+
+	quote, err = s.repo.CreateQuote(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "quote created", "quote_id", quote.ID, "providers", len(providers), "options", len(quote.Options))
+	return quote, nil
+}
+
+// quoteOption prices the parcel by the tariffs of the provider, or asks the provider when it has no tariffs.
+func (s *LogisticService) quoteOption(ctx context.Context, provider *domain.Provider, request *domain.QuoteCreateRequest) (*domain.QuoteOption, *errors.AppError) {
+	option := &domain.QuoteOption{ProviderID: provider.ID, ProviderName: provider.Name}
+	weight := request.Parcel.ChargeableWeight
+
+	tariffs, err := s.repo.ListTariffs(ctx, provider.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tariffs) > 0 {
+		tariff := domain.TariffFor(tariffs, weight)
+		if tariff == nil {
+			return nil, errors.NotFoundError(fmt.Errorf("no tariff for %.3f kg", weight))
+		}
+		option.Price, option.DeliveryDays, option.Source = tariff.Price, float32(tariff.DeliveryDays), domain.GetQuoteSource().Tariff
+		return option, nil
+	}
+	if provider.QuoteUrl == nil {
+		return nil, errors.NotFoundError(fmt.Errorf("no tariffs and no quote url"))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, configs.ProviderQuoteTimeout)
+	defer cancel()
+	quoted, err := s.providers.Quote(ctx, provider, &domain.ProviderQuoteRequest{
+		PickupPostalCode:  request.PickupPostalCode,
+		DropoffPostalCode: request.DropoffPostalCode,
+		Parcel:            &request.Parcel,
+		ChargeableWeight:  weight,
+	})
+	if err != nil {
+		return nil, err
+	}
+	option.Price, option.DeliveryDays, option.Source = quoted.Price, quoted.DeliveryDays, domain.GetQuoteSource().Provider
+	return option, nil
+}
+
+// checkQuote makes sure the quote belongs to the sender, has not expired and holds an option of the provider,
+// and returns the quote with the price of the option.
+func (s *LogisticService) checkQuote(ctx context.Context, request *domain.OrderCreateRequest, senderID uint) (*domain.Quote, *int64, *errors.AppError) {
+	if request.QuoteID == nil {
+		return nil, nil, nil
+	}
+	quote, err := s.repo.GetQuote(ctx, *request.QuoteID, senderID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, nil, errors.ValidationError(map[string]string{"quote_id": "does not exist"})
+		}
+		return nil, nil, err
+	}
+	if quote.Expired() {
+		return nil, nil, errors.UnprocessableEntity(errors.CodeQuoteExpired, "the quote expired, request a new one")
+	}
+	option := quote.Option(request.ProviderID)
+	if option == nil {
+		return nil, nil, errors.ValidationError(map[string]string{"provider_id": "is not an option of the quote"})
+	}
+	return quote, &option.Price, nil
+}
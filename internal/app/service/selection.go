@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"time"
+)
+
+// roundRobinWindow is the period whose orders are counted as the load of the providers.
+var roundRobinWindow = 24 * time.Hour
+
+// selectionStrategy scores the candidate providers of an order, candidates without a score had no data to be scored by.
+// The quote is the one of the order, if any.
+type selectionStrategy interface {
+	scores(ctx context.Context, candidates []*domain.Provider, quote *domain.Quote) (map[uint]float64, *errors.AppError)
+	// better reports whether the first score beats the second one
+	better(a, b float64) bool
+}
+
+// providerSelection is the provider selected for an order, with the strategy and the scores of the candidates.
+type providerSelection struct {
+	providerID uint
+	strategy   string
+	scores     []*domain.ProviderScore
+}
+
+func newSelectionStrategies(s *LogisticService) map[string]selectionStrategy {
+	return map[string]selectionStrategy{
+		domain.GetSelectionStrategy().Cheapest:   &cheapestStrategy{s},
+		domain.GetSelectionStrategy().Fastest:    &fastestStrategy{s},
+		domain.GetSelectionStrategy().OnTime:     &onTimeStrategy{s},
+		domain.GetSelectionStrategy().RoundRobin: &roundRobinStrategy{s},
+	}
+}
+
+// CheckSelectionStrategy reports an error when PROVIDER_SELECTION_STRATEGY names no strategy, so a misconfigured
+// server fails at startup rather than on every order sent without a provider.
+func CheckSelectionStrategy() error {
+	if _, ok := newSelectionStrategies(nil)[configs.ProviderSelectionStrategy]; !ok {
+		return fmt.Errorf("unknown provider selection strategy %q", configs.ProviderSelectionStrategy)
+	}
+	return nil
+}
+
+// selectProvider selects a provider taking orders between the addresses of the order by the strategy of the request,
+// or the configured strategy. An order with a quote only has the options of the quote as candidates.
+// The best scored candidate is selected, or the first one when none could be scored.
+func (s *LogisticService) selectProvider(ctx context.Context, request *domain.OrderCreateRequest, senderID uint) (*providerSelection, *errors.AppError) {
+	name := configs.ProviderSelectionStrategy
+	if request.Strategy != nil {
+		name = *request.Strategy
+	}
+	strategy, ok := s.strategies[name]
+	if !ok {
+		return nil, errors.InternalServerError(fmt.Errorf("unknown provider selection strategy %q", name))
+	}
+
+	order, err := s.repo.PreviewOrder(ctx, &domain.Order{
+		SenderID:         senderID,
+		ReceiverID:       request.ReceiverID,
+		PickupAddressID:  request.PickupAddressID,
+		DropoffAddressID: request.DropoffAddressID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.repo.GetQuotableProviders(ctx, order.PickupPostalCode, order.DropoffPostalCode)
+	if err != nil {
+		return nil, err
+	}
+	var quote *domain.Quote
+	if request.QuoteID != nil {
+		if quote, err = s.repo.GetQuote(ctx, *request.QuoteID, senderID); err != nil {
+			if err.Code == http.StatusNotFound {
+				return nil, errors.ValidationError(map[string]string{"quote_id": "does not exist"})
+			}
+			return nil, err
+		}
+		quoted := candidates[:0:0]
+		for _, candidate := range candidates {
+			if quote.Option(candidate.ID) != nil {
+				quoted = append(quoted, candidate)
+			}
+		}
+		candidates = quoted
+	}
+	if len(candidates) == 0 {
+		return nil, errors.UnprocessableEntity(errors.CodeNoProviderAvailable, "no provider takes orders between the addresses")
+	}
+
+	scores, err := strategy.scores(ctx, candidates, quote)
+	if err != nil {
+		return nil, err
+	}
+	selection := &providerSelection{providerID: candidates[0].ID, strategy: name}
+	var best *float64
+	for _, candidate := range candidates {
+		score := &domain.ProviderScore{ProviderID: candidate.ID}
+		if value, ok := scores[candidate.ID]; ok {
+			score.Score = &value
+			if best == nil || strategy.better(value, *best) {
+				best, selection.providerID = &value, candidate.ID
+			}
+		}
+		selection.scores = append(selection.scores, score)
+	}
+	slog.InfoContext(ctx, "provider selected", "strategy", name, "provider_id", selection.providerID, "candidates", len(candidates))
+	return selection, nil
+}
+
+// cheapestStrategy scores the candidates by their price in the quote of the order.
+type cheapestStrategy struct{ s *LogisticService }
+
+func (c *cheapestStrategy) scores(ctx context.Context, candidates []*domain.Provider, quote *domain.Quote) (map[uint]float64, *errors.AppError) {
+	if quote == nil {
+		return nil, errors.ValidationError(map[string]string{"quote_id": "is required by the cheapest strategy"})
+	}
+	scores := make(map[uint]float64)
+	for _, candidate := range candidates {
+		if option := quote.Option(candidate.ID); option != nil {
+			scores[candidate.ID] = float64(option.Price)
+		}
+	}
+	return scores, nil
+}
+
+func (c *cheapestStrategy) better(a, b float64) bool { return a < b }
+
+// fastestStrategy scores the candidates by the mean delivery time of their orders of the past 7 days.
+type fastestStrategy struct{ s *LogisticService }
+
+func (f *fastestStrategy) scores(ctx context.Context, candidates []*domain.Provider, quote *domain.Quote) (map[uint]float64, *errors.AppError) {
+	meanTimes, err := f.s.repo.GetProvidersMeanDeliveryTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64)
+	for _, meanTime := range meanTimes {
+		scores[meanTime.ProviderID] = float64(meanTime.MeanDeliveryTimeInDays)
+	}
+	return scores, nil
+}
+
+func (f *fastestStrategy) better(a, b float64) bool { return a < b }
+
+// onTimeStrategy scores the candidates by the share of their orders of the past 30 days delivered
+// within ON_TIME_DELIVERY_DAYS days of the pickup.
+type onTimeStrategy struct{ s *LogisticService }
+
+func (o *onTimeStrategy) scores(ctx context.Context, candidates []*domain.Provider, quote *domain.Quote) (map[uint]float64, *errors.AppError) {
+	rates, err := o.s.repo.GetProvidersOnTimeRate(ctx, configs.OnTimeDeliveryDays)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64)
+	for _, rate := range rates {
+		scores[rate.ProviderID] = rate.OnTimeRate
+	}
+	return scores, nil
+}
+
+func (o *onTimeStrategy) better(a, b float64) bool { return a > b }
+
+// roundRobinStrategy scores the candidates by their orders of the past day divided by their selection weight,
+// so the orders are spread over the providers in proportion to their weights.
+type roundRobinStrategy struct{ s *LogisticService }
+
+func (r *roundRobinStrategy) scores(ctx context.Context, candidates []*domain.Provider, quote *domain.Quote) (map[uint]float64, *errors.AppError) {
+	loads, err := r.s.repo.GetProvidersLoad(ctx, time.Now().Add(-roundRobinWindow))
+	if err != nil {
+		return nil, err
+	}
+	orders := make(map[uint]int64)
+	for _, load := range loads {
+		orders[load.ProviderID] = load.Orders
+	}
+	scores := make(map[uint]float64)
+	for _, candidate := range candidates {
+		scores[candidate.ID] = float64(orders[candidate.ID]) / float64(max(candidate.SelectionWeight, 1))
+	}
+	return scores, nil
+}
+
+func (r *roundRobinStrategy) better(a, b float64) bool { return a < b }
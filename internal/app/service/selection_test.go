@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"github.com/stretchr/testify/assert"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// selectionRepo serves the candidates and the statistics the strategies score them by.
+type selectionRepo struct {
+	ports.Repo
+	candidates []*domain.Provider
+	quote      *domain.Quote
+	meanTimes  []*domain.ProviderByDeliveryTime
+	onTime     []*domain.ProviderOnTimeRate
+	loads      []*domain.ProviderLoad
+}
+
+func (r *selectionRepo) PreviewOrder(ctx context.Context, order *domain.Order) (*domain.Order, *errors.AppError) {
+	order.PickupPostalCode, order.DropoffPostalCode = "11111", "22222"
+	return order, nil
+}
+
+func (r *selectionRepo) GetQuotableProviders(ctx context.Context, pickupPostalCode, dropoffPostalCode string) ([]*domain.Provider, *errors.AppError) {
+	return r.candidates, nil
+}
+
+func (r *selectionRepo) GetQuote(ctx context.Context, quoteID, customerID uint) (*domain.Quote, *errors.AppError) {
+	if r.quote == nil || r.quote.ID != quoteID || r.quote.CustomerID != customerID {
+		return nil, errors.NotFoundError(stdErrors.New("quote not found"))
+	}
+	return r.quote, nil
+}
+
+func (r *selectionRepo) GetProvidersMeanDeliveryTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError) {
+	return r.meanTimes, nil
+}
+
+func (r *selectionRepo) GetProvidersOnTimeRate(ctx context.Context, days int) ([]*domain.ProviderOnTimeRate, *errors.AppError) {
+	return r.onTime, nil
+}
+
+func (r *selectionRepo) GetProvidersLoad(ctx context.Context, since time.Time) ([]*domain.ProviderLoad, *errors.AppError) {
+	return r.loads, nil
+}
+
+// selectionGateway panics on any provider call, selecting a provider never reaches the providers.
+type selectionGateway struct {
+	ports.ProviderGateway
+}
+
+func TestSelectProvider(t *testing.T) {
+	strategy := domain.GetSelectionStrategy()
+	candidates := []*domain.Provider{
+		{ID: 1, SelectionWeight: 1},
+		{ID: 2, SelectionWeight: 3},
+		{ID: 3, SelectionWeight: 0},
+	}
+	quoteID := uint(7)
+
+	tests := []struct {
+		name     string
+		strategy string
+		repo     *selectionRepo
+		provider uint
+		scores   map[uint]float64
+		// quoted are the candidates left by the quote, every candidate is left when nil
+		quoted []uint
+	}{
+		{
+			name:     "Cheapest Selects The Lowest Quoted Price",
+			strategy: strategy.Cheapest,
+			repo: &selectionRepo{quote: &domain.Quote{ID: quoteID, CustomerID: 5, Options: []*domain.QuoteOption{
+				{ProviderID: 1, Price: 90_000},
+				{ProviderID: 3, Price: 60_000},
+			}}},
+			provider: 3,
+			scores:   map[uint]float64{1: 90_000, 3: 60_000},
+			quoted:   []uint{1, 3},
+		},
+		{
+			name:     "A Quote Limits The Candidates To Its Options",
+			strategy: strategy.Fastest,
+			repo: &selectionRepo{
+				quote: &domain.Quote{ID: quoteID, CustomerID: 5, Options: []*domain.QuoteOption{
+					{ProviderID: 1, Price: 90_000},
+					{ProviderID: 3, Price: 60_000},
+				}},
+				meanTimes: []*domain.ProviderByDeliveryTime{
+					{ProviderID: 1, MeanDeliveryTimeInDays: 3},
+					{ProviderID: 2, MeanDeliveryTimeInDays: 1.5},
+				},
+			},
+			provider: 1,
+			scores:   map[uint]float64{1: 3},
+			quoted:   []uint{1, 3},
+		},
+		{
+			name:     "Fastest Selects The Lowest Mean Delivery Time",
+			strategy: strategy.Fastest,
+			repo: &selectionRepo{meanTimes: []*domain.ProviderByDeliveryTime{
+				{ProviderID: 1, MeanDeliveryTimeInDays: 3},
+				{ProviderID: 2, MeanDeliveryTimeInDays: 1.5},
+			}},
+			provider: 2,
+			scores:   map[uint]float64{1: 3, 2: 1.5},
+		},
+		{
+			name:     "On Time Selects The Highest On Time Rate",
+			strategy: strategy.OnTime,
+			repo: &selectionRepo{onTime: []*domain.ProviderOnTimeRate{
+				{ProviderID: 1, Delivered: 10, OnTimeRate: 0.9},
+				{ProviderID: 2, Delivered: 10, OnTimeRate: 0.6},
+				{ProviderID: 3, Delivered: 4, OnTimeRate: 0.95},
+			}},
+			provider: 3,
+			scores:   map[uint]float64{1: 0.9, 2: 0.6, 3: 0.95},
+		},
+		{
+			name:     "Round Robin Divides The Load By The Selection Weight",
+			strategy: strategy.RoundRobin,
+			repo: &selectionRepo{loads: []*domain.ProviderLoad{
+				{ProviderID: 1, Orders: 4},
+				{ProviderID: 2, Orders: 9},
+				{ProviderID: 3, Orders: 5},
+			}},
+			provider: 2,
+			scores:   map[uint]float64{1: 4, 2: 3, 3: 5},
+		},
+		{
+			name:     "Round Robin Scores Providers Without Orders",
+			strategy: strategy.RoundRobin,
+			repo:     &selectionRepo{loads: []*domain.ProviderLoad{{ProviderID: 1, Orders: 2}}},
+			provider: 2,
+			scores:   map[uint]float64{1: 2, 2: 0, 3: 0},
+		},
+		{
+			name:     "The First Candidate Is Selected When None Is Scored",
+			strategy: strategy.Fastest,
+			repo:     &selectionRepo{},
+			provider: 1,
+			scores:   map[uint]float64{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.repo.candidates = candidates
+			s := NewLogisticService(test.repo, &selectionGateway{})
+			request := &domain.OrderCreateRequest{Strategy: &test.strategy}
+			if test.repo.quote != nil {
+				request.QuoteID = &quoteID
+			}
+
+			selection, err := s.selectProvider(context.Background(), request, 5)
+			assert.Nil(t, err)
+			assert.Equal(t, test.provider, selection.providerID)
+			assert.Equal(t, test.strategy, selection.strategy)
+			if test.quoted == nil {
+				assert.Len(t, selection.scores, len(candidates))
+			} else {
+				assert.Len(t, selection.scores, len(test.quoted))
+			}
+			for _, score := range selection.scores {
+				if test.quoted != nil {
+					assert.Contains(t, test.quoted, score.ProviderID)
+				}
+				if value, ok := test.scores[score.ProviderID]; ok {
+					assert.NotNil(t, score.Score)
+					assert.Equal(t, value, *score.Score)
+				} else {
+					assert.Nil(t, score.Score)
+				}
+			}
+		})
+	}
+
+	t.Run("Configured Strategy Is Used Without One In The Request", func(t *testing.T) {
+		defer func(name string) { configs.ProviderSelectionStrategy = name }(configs.ProviderSelectionStrategy)
+		configs.ProviderSelectionStrategy = strategy.RoundRobin
+		s := NewLogisticService(&selectionRepo{candidates: candidates}, &selectionGateway{})
+
+		selection, err := s.selectProvider(context.Background(), &domain.OrderCreateRequest{}, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, strategy.RoundRobin, selection.strategy)
+	})
+
+	t.Run("Cheapest Requires A Quote Of The Sender", func(t *testing.T) {
+		cheapest := strategy.Cheapest
+		s := NewLogisticService(&selectionRepo{candidates: candidates, quote: &domain.Quote{ID: quoteID, CustomerID: 5}}, &selectionGateway{})
+
+		_, err := s.selectProvider(context.Background(), &domain.OrderCreateRequest{Strategy: &cheapest}, 5)
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+
+		_, err = s.selectProvider(context.Background(), &domain.OrderCreateRequest{Strategy: &cheapest, QuoteID: &quoteID}, 6)
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	})
+
+	t.Run("No Provider Available Without Quoted Candidates", func(t *testing.T) {
+		fastest := strategy.Fastest
+		quote := &domain.Quote{ID: quoteID, CustomerID: 5, Options: []*domain.QuoteOption{{ProviderID: 4, Price: 50_000}}}
+		s := NewLogisticService(&selectionRepo{candidates: candidates, quote: quote}, &selectionGateway{})
+
+		_, err := s.selectProvider(context.Background(), &domain.OrderCreateRequest{Strategy: &fastest, QuoteID: &quoteID}, 5)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+	})
+
+	t.Run("No Provider Available Without Candidates", func(t *testing.T) {
+		fastest := strategy.Fastest
+		s := NewLogisticService(&selectionRepo{}, &selectionGateway{})
+
+		_, err := s.selectProvider(context.Background(), &domain.OrderCreateRequest{Strategy: &fastest}, 5)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+	})
+}
+
+func TestCheckSelectionStrategy(t *testing.T) {
+	defer func(name string) { configs.ProviderSelectionStrategy = name }(configs.ProviderSelectionStrategy)
+
+	for _, name := range []string{"cheapest", "fastest", "on_time", "round_robin"} {
+		configs.ProviderSelectionStrategy = name
+		assert.NoError(t, CheckSelectionStrategy())
+	}
+
+	configs.ProviderSelectionStrategy = "fastes"
+	assert.Error(t, CheckSelectionStrategy())
+}
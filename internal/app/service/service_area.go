@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+)
+
+func (s *LogisticService) ListServiceAreas(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.ServiceArea, *errors.AppError) {
+	return s.repo.ListServiceAreas(ctx, request.ProviderID)
+}
+
+// ImportServiceAreas adds the service areas of the CSV to the provider, or replaces its service areas.
+// Nothing is imported when a row is invalid.
+func (s *LogisticService) ImportServiceAreas(ctx context.Context, request *domain.ServiceAreaImportRequest) ([]*domain.ServiceArea, *errors.AppError) {
+	areas, invalid := domain.ParseServiceAreas(request.CSV)
+	if invalid != nil {
+		return nil, errors.ValidationError(invalid)
+	}
+
+	all, err := s.repo.ImportServiceAreas(ctx, request.ProviderID, areas, request.Replace)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "service areas imported", "provider_id", request.ProviderID,
+		"imported", len(areas), "total", len(all), "replace", request.Replace)
+	return all, nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/app/ports"
+	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/telemetry"
+)
+
+// TracedService wraps a ports.Service and creates a span around each of its methods.
+type TracedService struct {
+	next ports.Service
+}
+
+func WithTracing(next ports.Service) *TracedService {
+	return &TracedService{next: next}
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return telemetry.Tracer().Start(ctx, "LogisticService."+method)
+}
+
+func endSpan(span trace.Span, err *errors.AppError) {
+	if err != nil {
+		telemetry.End(span, err.Err)
+		return
+	}
+	span.End()
+}
+
+func (t *TracedService) Liveness(ctx context.Context) (*domain.HealthReport, *errors.AppError) {
+	ctx, span := startSpan(ctx, "Liveness")
+	result, err := t.next.Liveness(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) Readiness(ctx context.Context) (*domain.HealthReport, *errors.AppError) {
+	ctx, span := startSpan(ctx, "Readiness")
+	result, err := t.next.Readiness(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetProviders(ctx context.Context, request *domain.ProviderListRequest) (*domain.Page[*domain.Provider], *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetProviders")
+	result, err := t.next.GetProviders(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetProvidersMeanDelTime(ctx context.Context) ([]*domain.ProviderByDeliveryTime, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetProvidersMeanDelTime")
+	result, err := t.next.GetProvidersMeanDelTime(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) CreateProvider(ctx context.Context, request *domain.ProviderCreateRequest) (*domain.Provider, *errors.AppError) {
+	ctx, span := startSpan(ctx, "CreateProvider")
+	result, err := t.next.CreateProvider(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetProvider")
+	result, err := t.next.GetProvider(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) UpdateProvider(ctx context.Context, request *domain.ProviderUpdateRequest) (*domain.Provider, *errors.AppError) {
+	ctx, span := startSpan(ctx, "UpdateProvider")
+	result, err := t.next.UpdateProvider(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) VerifyProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError) {
+	ctx, span := startSpan(ctx, "VerifyProvider")
+	result, err := t.next.VerifyProvider(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ListServiceAreas(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.ServiceArea, *errors.AppError) {
+	ctx, span := startSpan(ctx, "ListServiceAreas")
+	result, err := t.next.ListServiceAreas(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ImportServiceAreas(ctx context.Context, request *domain.ServiceAreaImportRequest) ([]*domain.ServiceArea, *errors.AppError) {
+	ctx, span := startSpan(ctx, "ImportServiceAreas")
+	result, err := t.next.ImportServiceAreas(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ListTariffs(ctx context.Context, request *domain.ProviderGetRequest) ([]*domain.Tariff, *errors.AppError) {
+	ctx, span := startSpan(ctx, "ListTariffs")
+	result, err := t.next.ListTariffs(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) UpdateTariffs(ctx context.Context, request *domain.TariffsUpdateRequest) ([]*domain.Tariff, *errors.AppError) {
+	ctx, span := startSpan(ctx, "UpdateTariffs")
+	result, err := t.next.UpdateTariffs(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) CreateQuote(ctx context.Context, request *domain.QuoteCreateRequest) (*domain.Quote, *errors.AppError) {
+	ctx, span := startSpan(ctx, "CreateQuote")
+	result, err := t.next.CreateQuote(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) CreateCustomer(ctx context.Context, request *domain.CustomerCreateRequest) (*domain.Customer, *errors.AppError) {
+	ctx, span := startSpan(ctx, "CreateCustomer")
+	result, err := t.next.CreateCustomer(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetCustomerToken(ctx context.Context, request *domain.CustomerTokenRequest) (*domain.CustomerToken, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetCustomerToken")
+	result, err := t.next.GetCustomerToken(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetProfile(ctx context.Context) (*domain.Customer, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetProfile")
+	result, err := t.next.GetProfile(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) UpdateProfile(ctx context.Context, request *domain.CustomerUpdateRequest) (*domain.Customer, *errors.AppError) {
+	ctx, span := startSpan(ctx, "UpdateProfile")
+	result, err := t.next.UpdateProfile(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) VerifyPhoneNumber(ctx context.Context, request *domain.PhoneVerifyRequest) (*domain.Customer, *errors.AppError) {
+	ctx, span := startSpan(ctx, "VerifyPhoneNumber")
+	result, err := t.next.VerifyPhoneNumber(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ListAddresses(ctx context.Context) ([]*domain.Address, *errors.AppError) {
+	ctx, span := startSpan(ctx, "ListAddresses")
+	result, err := t.next.ListAddresses(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) CreateAddress(ctx context.Context, request *domain.AddressCreateRequest) (*domain.Address, *errors.AppError) {
+	ctx, span := startSpan(ctx, "CreateAddress")
+	result, err := t.next.CreateAddress(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetAddress")
+	result, err := t.next.GetAddress(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) UpdateAddress(ctx context.Context, request *domain.AddressUpdateRequest) (*domain.Address, *errors.AppError) {
+	ctx, span := startSpan(ctx, "UpdateAddress")
+	result, err := t.next.UpdateAddress(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) DeleteAddress(ctx context.Context, request *domain.AddressGetRequest) (*domain.Address, *errors.AppError) {
+	ctx, span := startSpan(ctx, "DeleteAddress")
+	result, err := t.next.DeleteAddress(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) CreateOrder(ctx context.Context, request *domain.OrderCreateRequest) (*domain.Order, *errors.AppError) {
+	ctx, span := startSpan(ctx, "CreateOrder")
+	result, err := t.next.CreateOrder(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetOrder(ctx context.Context, request *domain.OrderGetRequest) (*domain.Order, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetOrder")
+	result, err := t.next.GetOrder(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) GetTracking(ctx context.Context, request *domain.TrackingGetRequest) (*domain.Tracking, *errors.AppError) {
+	ctx, span := startSpan(ctx, "GetTracking")
+	result, err := t.next.GetTracking(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ListIncomingOrders(ctx context.Context, request *domain.OrderListRequest) (*domain.Page[*domain.Order], *errors.AppError) {
+	ctx, span := startSpan(ctx, "ListIncomingOrders")
+	result, err := t.next.ListIncomingOrders(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) ListDeliverySlots(ctx context.Context, request *domain.OrderGetRequest) ([]*domain.DeliverySlot, *errors.AppError) {
+	ctx, span := startSpan(ctx, "ListDeliverySlots")
+	result, err := t.next.ListDeliverySlots(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *TracedService) UpdateDelivery(ctx context.Context, request *domain.OrderDeliveryUpdateRequest) (*domain.Order, *errors.AppError) {
+	ctx, span := startSpan(ctx, "UpdateDelivery")
+	result, err := t.next.UpdateDelivery(ctx, request)
+	endSpan(span, err)
+	return result, err
+}
+
+// ScheduleUpdateOrderStatus never returns, each run of the task is traced on its own.
+func (t *TracedService) ScheduleUpdateOrderStatus() {
+	t.next.ScheduleUpdateOrderStatus()
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/errors"
+	"logistic-app/internal/common/logger"
+	"strings"
+)
+
+// GetTracking returns the public view of the order with the tracking code.
+func (s *LogisticService) GetTracking(ctx context.Context, request *domain.TrackingGetRequest) (*domain.Tracking, *errors.AppError) {
+	order, err := s.repo.GetTrackedOrder(ctx, strings.ToUpper(request.Code))
+	if err != nil {
+		return nil, err
+	}
+	ctx = logger.WithOrderID(ctx, order.ID)
+	provider, err := s.repo.GetProvider(ctx, order.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTracking(order, provider), nil
+}
@@ -2,14 +2,28 @@ package service
 
 import (
 	"context"
+	"log/slog"
 	"logistic-app/internal/app/domain"
 )
 
-func (s *LogisticService) NotifyReceiver(order *domain.Order) {
+func (s *LogisticService) NotifyReceiver(ctx context.Context, order *domain.Order) {
 	if order.NotifiedReceiver {
 		return
 	} else {
-		s.repo.UpdateOrderNotification(context.Background(), order.ID)
+		if err := s.repo.UpdateOrderNotification(ctx, order.ID); err != nil {
+			slog.ErrorContext(ctx, "could not mark receiver as notified", "error", err.Err)
+			return
+		}
+		slog.InfoContext(ctx, "notifying receiver", "receiver_id", order.ReceiverID)
 		// todo: send a message to user
 	}
 }
+
+// verificationCodesDelivered reports whether SendVerificationCode reaches the phone numbers. There is no SMS sender
+// yet, so phone number changes are refused instead of being kept pending with a code sent nowhere.
+const verificationCodesDelivered = false
+
+func (s *LogisticService) SendVerificationCode(ctx context.Context, phone, code string) {
+	slog.InfoContext(ctx, "sending phone verification code")
+	// todo: send an SMS to the phone number
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"logistic-app/internal/app/domain"
+	"logistic-app/internal/common/configs"
+	"logistic-app/internal/common/errors"
+	"net/http"
+)
+
+// verificationFailure is the detail stored on providers failing the probe, the reason is only logged as the
+// detail is served to anyone reading the provider.
+const verificationFailure = "the url did not respond with the statuses of an order"
+
+// VerifyProvider probes the url of the provider again in the background, the provider takes orders once it
+// responds as expected. The provider is returned as it is before the probe.
+func (s *LogisticService) VerifyProvider(ctx context.Context, request *domain.ProviderGetRequest) (*domain.Provider, *errors.AppError) {
+	provider, err := s.repo.GetProvider(ctx, request.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	go s.verifyProvider(context.WithoutCancel(ctx), provider)
+	return provider, nil
+}
+
+// verifyProvider probes the url of the provider, with the test order reference when one is configured,
+// and stores the result. A provider failing the probe is kept pending with a generic detail.
+func (s *LogisticService) verifyProvider(ctx context.Context, provider *domain.Provider) {
+	probeCtx, cancel := context.WithTimeout(ctx, configs.ProviderVerificationTimeout)
+	defer cancel()
+
+	status := domain.GetProviderVerificationStatus().Verified
+	var detail *string
+	if err := s.providers.Verify(probeCtx, provider, configs.ProviderVerificationReference); err != nil {
+		status = domain.GetProviderVerificationStatus().Pending
+		reason := verificationFailure
+		detail = &reason
+		slog.WarnContext(ctx, "provider verification failed", "provider_id", provider.ID, "error", err.Err)
+	}
+
+	if _, err := s.repo.UpdateProviderVerification(ctx, provider.ID, provider.Url, status, detail); err != nil {
+		if err.Code == http.StatusNotFound {
+			slog.InfoContext(ctx, "provider verification discarded, the url changed", "provider_id", provider.ID)
+			return
+		}
+		slog.ErrorContext(ctx, "could not save the provider verification", "provider_id", provider.ID, "error", err.Err)
+		return
+	}
+	slog.InfoContext(ctx, "provider verification checked", "provider_id", provider.ID, "status", status)
+}
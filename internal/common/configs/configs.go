@@ -9,6 +9,8 @@ type AuthStatusVal int
 const (
 	UserIDKey intKey = iota
 	AuthStatusKey
+	RequestIDKey
+	OrderIDKey
 )
 
 // values for AuthStatusKey in context
@@ -19,8 +21,17 @@ const (
 
 var TokenExpiration = time.Duration(intEnv("TOKEN_EXPIRATION", 24)) * time.Hour
 var SecretKey = stringEnv("SECRET_KEY", "random_secret_key")
+var AdminToken = stringEnv("ADMIN_TOKEN", "")
 var ServerURL = stringEnv("SERVER_URL", "localhost:8080")
 var LogError = boolEnv("LOG_ERROR", true)
+var LogLevel = stringEnv("LOG_LEVEL", "info")
+var SlowQueryThreshold = time.Duration(intEnv("SLOW_QUERY_THRESHOLD", 200)) * time.Millisecond
+
+var TraceExporter = stringEnv("TRACE_EXPORTER", "none")
+var TraceSampleRatio = floatEnv("TRACE_SAMPLE_RATIO", 1)
+
+var MaxBodySize = int64(intEnv("MAX_BODY_SIZE", 1<<20))
+var RejectUnknownFields = boolEnv("REJECT_UNKNOWN_FIELDS", false)
 
 var JWTDefaults = map[string]any{
 	"AUTH_HEADER_TYPES": []string{"Bearer"},
@@ -43,3 +54,38 @@ var DBTestName = stringEnv("DB_TEST_NAME", "postgres")
 
 var OrderUpdatePeriod = time.Duration(intEnv("ORDER_UPDATE_PERIOD", 24*60*60)) * time.Second
 var PeriodicTaskMaxConcurrency = intEnv("PERIODIC_TASK_MAX_CONCURRENCY", 10)
+
+var RateLimitStore = stringEnv("RATE_LIMIT_STORE", "memory")
+var RateLimitTrustProxy = boolEnv("RATE_LIMIT_TRUST_PROXY", false)
+
+var IdempotencyKeyTTL = time.Duration(intEnv("IDEMPOTENCY_KEY_TTL", 24)) * time.Hour
+
+var PhoneVerificationTTL = time.Duration(intEnv("PHONE_VERIFICATION_TTL", 10)) * time.Minute
+var PhoneVerificationMaxAttempts = intEnv("PHONE_VERIFICATION_MAX_ATTEMPTS", 5)
+
+var ProviderRequestTimeout = time.Duration(intEnv("PROVIDER_REQUEST_TIMEOUT", 30)) * time.Second
+var ProviderAllowPrivateNetworks = boolEnv("PROVIDER_ALLOW_PRIVATE_NETWORKS", false)
+var BookingMaxAttempts = intEnv("BOOKING_MAX_ATTEMPTS", 5)
+var ProviderVerificationTimeout = time.Duration(intEnv("PROVIDER_VERIFICATION_TIMEOUT", 10)) * time.Second
+var ProviderVerificationReference = stringEnv("PROVIDER_VERIFICATION_REFERENCE", "")
+var ProviderQuoteTimeout = time.Duration(intEnv("PROVIDER_QUOTE_TIMEOUT", 5)) * time.Second
+var QuoteTTL = time.Duration(intEnv("QUOTE_TTL", 15)) * time.Minute
+
+var ProviderSelectionStrategy = stringEnv("PROVIDER_SELECTION_STRATEGY", "fastest")
+var OnTimeDeliveryDays = intEnv("ON_TIME_DELIVERY_DAYS", 3)
+
+var DeliverySlotDays = intEnv("DELIVERY_SLOT_DAYS", 5)
+var DeliverySlotHours = intEnv("DELIVERY_SLOT_HOURS", 4)
+var DeliveryDayStart = intEnv("DELIVERY_DAY_START", 9)
+var DeliveryDayEnd = intEnv("DELIVERY_DAY_END", 21)
+
+var EstimateHistoryDays = intEnv("ESTIMATE_HISTORY_DAYS", 90)
+var EstimateMinSamples = intEnv("ESTIMATE_MIN_SAMPLES", 5)
+var EstimateRegionDigits = intEnv("ESTIMATE_REGION_DIGITS", 2)
+var EstimateMaxSamples = intEnv("ESTIMATE_MAX_SAMPLES", 1000)
+
+var HealthCheckTimeout = time.Duration(intEnv("HEALTH_CHECK_TIMEOUT", 2)) * time.Second
+var HealthProviderCheckInterval = time.Duration(intEnv("HEALTH_PROVIDER_CHECK_INTERVAL", 60)) * time.Second
+
+var LegacyRoutes = stringEnv("LEGACY_ROUTES", "alias")
+var LegacyRoutesSunset = stringEnv("LEGACY_ROUTES_SUNSET", "")
@@ -15,6 +15,15 @@ func intEnv(key string, fallback int) int {
 	return intVal
 }
 
+func floatEnv(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return floatVal
+}
+
 func stringEnv(key, fallback string) string {
 	value := os.Getenv(key)
 	if value == "" {
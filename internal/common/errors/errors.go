@@ -1,60 +1,197 @@
 package errors
 
 import (
+	stdErrors "errors"
 	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"net/http"
+	"regexp"
 )
 
-type apiError struct {
-	Msg string `json:"errors"`
+// stable error codes returned to clients, new codes can be added but existing ones must not change
+const (
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeBadRequest           = "bad_request"
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeNotFound             = "not_found"
+	CodeAlreadyExists        = "already_exists"
+	CodeInvalidReference     = "invalid_reference"
+	CodeConstraintViolation  = "constraint_violation"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeIdempotencyInFlight  = "idempotency_key_in_progress"
+	CodeVerificationInvalid  = "invalid_verification_code"
+	CodeVerificationExpired  = "verification_expired"
+	CodePhoneChangeDisabled  = "phone_change_disabled"
+	CodeProviderInactive     = "provider_inactive"
+	CodeProviderUnverified   = "provider_unverified"
+	CodePostalCodeNotCovered = "postal_code_not_covered"
+	CodeQuoteExpired         = "quote_expired"
+	CodeQuoteMismatch        = "quote_mismatch"
+	CodeNoProviderAvailable  = "no_provider_available"
+	CodeOrderPickedUp        = "order_picked_up"
+	CodeProviderRejected     = "provider_rejected"
+	CodeInternal             = "internal_error"
+)
+
+const problemTypePrefix = "urn:problem-type:logistic-app:"
+
+// Problem is the RFC 7807 body of an error response.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   map[string]string `json:"errors,omitempty"`
 }
 
+// AppError keeps the problem shown to the client apart from the underlying error,
+// which is only logged.
 type AppError struct {
-	ApiErr *apiError
+	ApiErr *Problem
 	Err    error
 	Code   int
 }
 
-func Unauthorized() *AppError {
+func New(status int, code, detail string, err error) *AppError {
 	return &AppError{
-		ApiErr: &apiError{Msg: "Unauthorized"},
-		Err:    fmt.Errorf("unauthorized"),
-		Code:   http.StatusUnauthorized,
+		ApiErr: &Problem{
+			Type:   problemTypePrefix + code,
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: detail,
+			Code:   code,
+		},
+		Err:  err,
+		Code: status,
 	}
 }
 
+func (e *AppError) withFields(fields map[string]string) *AppError {
+	e.ApiErr.Errors = fields
+	return e
+}
+
+func Unauthorized() *AppError {
+	return New(http.StatusUnauthorized, CodeUnauthorized, "authentication credentials were not provided or are invalid",
+		fmt.Errorf("unauthorized"))
+}
+
+// Forbidden reports a request of an authenticated customer who is not allowed to do it.
+func Forbidden(msg string) *AppError {
+	return New(http.StatusForbidden, CodeForbidden, msg, fmt.Errorf(msg))
+}
+
 func BadRequest(msg string) *AppError {
-	return &AppError{
-		ApiErr: &apiError{Msg: msg},
-		Err:    fmt.Errorf(msg),
-		Code:   http.StatusBadRequest,
-	}
+	return New(http.StatusBadRequest, CodeBadRequest, msg, fmt.Errorf(msg))
+}
+
+// InvalidRequest is a bad request caused by the given fields, keyed by their json path.
+func InvalidRequest(msg string, fields map[string]string) *AppError {
+	return New(http.StatusBadRequest, CodeInvalidRequest, msg, fmt.Errorf("%s: %v", msg, fields)).
+		withFields(fields)
+}
+
+// UnsupportedMediaType reports a body sent with another content type than the expected media type.
+func UnsupportedMediaType(contentType, expected string) *AppError {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "content type must be "+expected,
+		fmt.Errorf("unsupported content type %q", contentType))
+}
+
+func RequestEntityTooLarge(limit int64) *AppError {
+	return New(http.StatusRequestEntityTooLarge, CodeBodyTooLarge, fmt.Sprintf("body must not be larger than %d bytes", limit),
+		fmt.Errorf("body larger than %d bytes", limit))
+}
+
+// ValidationError reports every invalid field of a request, keyed by the json path of the field.
+func ValidationError(fields map[string]string) *AppError {
+	return New(http.StatusBadRequest, CodeValidationFailed, "request has invalid fields",
+		fmt.Errorf("validation failed: %v", fields)).
+		withFields(fields)
 }
 
+// InternalServerError hides the error from the client, it is still logged when the response is returned.
 func InternalServerError(err error) *AppError {
-	return &AppError{
-		ApiErr: &apiError{Msg: err.Error()},
-		Err:    err,
-		Code:   http.StatusInternalServerError,
-	}
+	return New(http.StatusInternalServerError, CodeInternal, "an unexpected error occurred", err)
 }
 
 func NotFoundError(err error) *AppError {
-	return &AppError{
-		ApiErr: &apiError{Msg: "object not found"},
-		Err:    err,
-		Code:   http.StatusNotFound,
-	}
+	return New(http.StatusNotFound, CodeNotFound, "object not found", err)
+}
+
+func Conflict(code, msg string) *AppError {
+	return New(http.StatusConflict, code, msg, fmt.Errorf(msg))
+}
+
+func UnprocessableEntity(code, msg string) *AppError {
+	return New(http.StatusUnprocessableEntity, code, msg, fmt.Errorf(msg))
+}
+
+// NotCovered reports the postal codes of the given fields which are outside the service areas of the provider.
+func NotCovered(fields map[string]string) *AppError {
+	return New(http.StatusUnprocessableEntity, CodePostalCodeNotCovered, "the provider does not serve the postal codes",
+		fmt.Errorf("postal codes not covered: %v", fields)).
+		withFields(fields)
+}
+
+func TooManyRequests() *AppError {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, "too many requests, retry later",
+		fmt.Errorf("rate limit exceeded"))
 }
 
+// pgKeyRegex reads the columns from the detail of postgres constraint errors, like
+// `Key (phone_number)=(+989121234567) already exists.`
+var pgKeyRegex = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
 func ConvertGormErrors(err error) *AppError {
-	switch err {
-	case nil:
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
 		return nil
-	case gorm.ErrRecordNotFound:
+	case stdErrors.Is(err, gorm.ErrRecordNotFound):
 		return NotFoundError(err)
+	case stdErrors.As(err, &pgErr):
+		return convertPgError(pgErr)
 	default:
 		return InternalServerError(err)
 	}
 }
+
+func convertPgError(pgErr *pgconn.PgError) *AppError {
+	var fields map[string]string
+	column := ""
+	if match := pgKeyRegex.FindStringSubmatch(pgErr.Detail); match != nil {
+		column = match[1]
+	} else if pgErr.ColumnName != "" {
+		column = pgErr.ColumnName
+	}
+
+	var appErr *AppError
+	switch pgErr.Code {
+	case "23505":
+		appErr = New(http.StatusConflict, CodeAlreadyExists, "an object with the same values already exists", pgErr)
+		if column != "" {
+			fields = map[string]string{column: "already exists"}
+		}
+	case "23503":
+		appErr = New(http.StatusConflict, CodeInvalidReference, "a referenced object does not exist or is still in use", pgErr)
+		if column != "" {
+			fields = map[string]string{column: "references an object which does not exist"}
+		}
+	default:
+		// other integrity constraint violations share the 23 class
+		if len(pgErr.Code) == 5 && pgErr.Code[:2] == "23" {
+			appErr = New(http.StatusConflict, CodeConstraintViolation, "the request conflicts with the stored data", pgErr)
+		} else {
+			return InternalServerError(pgErr)
+		}
+	}
+	return appErr.withFields(fields)
+}
@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"testing"
+)
+
+func TestConvertGormErrors(t *testing.T) {
+	t.Run("Not Found", func(t *testing.T) {
+		err := ConvertGormErrors(gorm.ErrRecordNotFound)
+		assert.Equal(t, http.StatusNotFound, err.Code)
+		assert.Equal(t, CodeNotFound, err.ApiErr.Code)
+	})
+
+	t.Run("Unique Violation", func(t *testing.T) {
+		err := ConvertGormErrors(&pgconn.PgError{
+			Code:   "23505",
+			Detail: "Key (phone_number)=(+989121234567) already exists.",
+		})
+		assert.Equal(t, http.StatusConflict, err.Code)
+		assert.Equal(t, CodeAlreadyExists, err.ApiErr.Code)
+		assert.Equal(t, map[string]string{"phone_number": "already exists"}, err.ApiErr.Errors)
+		assert.NotContains(t, err.ApiErr.Detail, "+989121234567")
+	})
+
+	t.Run("Foreign Key Violation", func(t *testing.T) {
+		err := ConvertGormErrors(fmt.Errorf("wrapped: %w", &pgconn.PgError{
+			Code:   "23503",
+			Detail: `Key (receiver_id)=(5) is not present in table "customers".`,
+		}))
+		assert.Equal(t, http.StatusConflict, err.Code)
+		assert.Equal(t, CodeInvalidReference, err.ApiErr.Code)
+		assert.Contains(t, err.ApiErr.Errors, "receiver_id")
+	})
+
+	t.Run("Internal Error Is Hidden", func(t *testing.T) {
+		err := ConvertGormErrors(fmt.Errorf("connection refused"))
+		assert.Equal(t, http.StatusInternalServerError, err.Code)
+		assert.Equal(t, CodeInternal, err.ApiErr.Code)
+		assert.NotContains(t, err.ApiErr.Detail, "connection refused")
+	})
+}
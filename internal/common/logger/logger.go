@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"logistic-app/internal/common/configs"
+	"os"
+	"strings"
+)
+
+// Init replaces the default slog logger with a JSON logger writing to stdout.
+// The standard log package is routed through it as well.
+func Init() {
+	slog.SetDefault(New(os.Stdout))
+}
+
+func New(w io.Writer) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: parseLevel(configs.LogLevel)})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// WithOrderID stores the order id in the context so that it is attached to every log line.
+func WithOrderID(ctx context.Context, orderID uint) context.Context {
+	return context.WithValue(ctx, configs.OrderIDKey, orderID)
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// contextHandler adds the request, user, order and trace ids found in the context to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if requestID, ok := ctx.Value(configs.RequestIDKey).(string); ok {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+		if userID, ok := ctx.Value(configs.UserIDKey).(uint); ok {
+			r.AddAttrs(slog.Uint64("user_id", uint64(userID)))
+		}
+		if orderID, ok := ctx.Value(configs.OrderIDKey).(uint); ok {
+			r.AddAttrs(slog.Uint64("order_id", uint64(orderID)))
+		}
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			r.AddAttrs(slog.String("trace_id", spanCtx.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
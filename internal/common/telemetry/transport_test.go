@@ -0,0 +1,34 @@
+package telemetry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	t.Run("Propagates Trace Context", func(t *testing.T) {
+		client := &http.Client{Transport: NewTransport(nil)}
+		request, err := http.NewRequest(http.MethodGet, server.URL, http.NoBody)
+		assert.Empty(t, err)
+
+		resp, err := client.Do(request)
+		assert.Empty(t, err)
+		_ = resp.Body.Close()
+		assert.NotEmpty(t, traceParent)
+		assert.Empty(t, request.Header.Get("traceparent"))
+	})
+}